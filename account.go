@@ -0,0 +1,111 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type Balance struct {
+	CurrencyCode string
+	Amount       Money
+}
+
+type BalanceResponse struct {
+	*PayPalResponse
+	Balances []Balance
+}
+
+// GetBalance returns the balance of the primary currency only, unless
+// returnAllCurrencies is set.
+func (pClient *PayPalClient) GetBalance(returnAllCurrencies bool) (*BalanceResponse, error) {
+	return pClient.GetBalanceCtx(context.Background(), returnAllCurrencies)
+}
+
+func (pClient *PayPalClient) GetBalanceCtx(ctx context.Context, returnAllCurrencies bool) (*BalanceResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "GetBalance")
+	if returnAllCurrencies {
+		values.Add("RETURNALLCURRENCIES", "1")
+	} else {
+		values.Add("RETURNALLCURRENCIES", "0")
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+
+	balances := &BalanceResponse{PayPalResponse: response}
+	for i := 0; ; i++ {
+		amountKey := fmt.Sprintf("%s%d", "L_AMT", i)
+		if _, ok := response.Values[amountKey]; !ok {
+			break
+		}
+		currencyCode := response.Values.Get(fmt.Sprintf("%s%d", "L_CURRENCYCODE", i))
+		balances.Balances = append(balances.Balances, Balance{
+			CurrencyCode: currencyCode,
+			Amount:       parseMoney(response.Values, amountKey, currencyCode),
+		})
+	}
+	return balances, err
+}
+
+type AddressVerifyResponse struct {
+	*PayPalResponse
+	ConfirmationCode string
+	StreetMatch      string
+	ZipMatch         string
+	CountryCode      string
+	Token            string
+}
+
+// AddressVerify checks a postal address against the one PayPal has on file for
+// the given email. StreetMatch is only meaningful when the ZIP matched.
+func (pClient *PayPalClient) AddressVerify(email, street, zip string) (*AddressVerifyResponse, error) {
+	return pClient.AddressVerifyCtx(context.Background(), email, street, zip)
+}
+
+func (pClient *PayPalClient) AddressVerifyCtx(ctx context.Context, email, street, zip string) (*AddressVerifyResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "AddressVerify")
+	values.Add("EMAIL", email)
+	values.Add("STREET", street)
+	values.Add("ZIP", zip)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &AddressVerifyResponse{
+		PayPalResponse:   response,
+		ConfirmationCode: response.Values.Get("CONFIRMATIONCODE"),
+		StreetMatch:      response.Values.Get("STREETMATCH"),
+		ZipMatch:         response.Values.Get("ZIPMATCH"),
+		CountryCode:      response.Values.Get("COUNTRYCODE"),
+		Token:            response.Values.Get("TOKEN"),
+	}, err
+}
+
+type PalDetailsResponse struct {
+	*PayPalResponse
+	Pal    string
+	Locale string
+}
+
+// GetPalDetails returns the merchant's PayPal Account Login (PAL) identifier and
+// locale, as used when rendering dynamic PayPal buttons.
+func (pClient *PayPalClient) GetPalDetails() (*PalDetailsResponse, error) {
+	return pClient.GetPalDetailsCtx(context.Background())
+}
+
+func (pClient *PayPalClient) GetPalDetailsCtx(ctx context.Context) (*PalDetailsResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "GetPalDetails")
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &PalDetailsResponse{response, response.Values.Get("PAL"), response.Values.Get("LOCALE")}, err
+}
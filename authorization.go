@@ -0,0 +1,172 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+type CaptureResponse struct {
+	*PayPalResponse
+	AuthorizationId     string
+	TransactionId       string
+	ParentTransactionId string
+	TransactionType     string
+	PaymentType         string
+	PaymentStatus       PaymentStatus
+	PendingReason       PendingReason
+	ReasonCode          ReasonCode
+	CurrencyCode        string
+	Amount              Money
+	FeeAmount           Money
+	TaxAmount           Money
+	SettleAmount        Money
+}
+
+func newCaptureResponse(r *PayPalResponse) *CaptureResponse {
+	return &CaptureResponse{
+		PayPalResponse:      r,
+		AuthorizationId:     r.Values.Get("AUTHORIZATIONID"),
+		TransactionId:       r.Values.Get("TRANSACTIONID"),
+		ParentTransactionId: r.Values.Get("PARENTTRANSACTIONID"),
+		TransactionType:     r.Values.Get("TRANSACTIONTYPE"),
+		PaymentType:         r.Values.Get("PAYMENTTYPE"),
+		PaymentStatus:       PaymentStatus(r.Values.Get("PAYMENTSTATUS")),
+		PendingReason:       PendingReason(r.Values.Get("PENDINGREASON")),
+		ReasonCode:          ReasonCode(r.Values.Get("REASONCODE")),
+		CurrencyCode:        r.Values.Get("CURRENCYCODE"),
+		Amount:              parseMoney(r.Values, "AMT", r.Values.Get("CURRENCYCODE")),
+		FeeAmount:           parseMoney(r.Values, "FEEAMT", r.Values.Get("CURRENCYCODE")),
+		TaxAmount:           parseMoney(r.Values, "TAXAMT", r.Values.Get("CURRENCYCODE")),
+		SettleAmount:        parseMoney(r.Values, "SETTLEAMT", ""),
+	}
+}
+
+func (r *CaptureResponse) NetAmount() Money {
+	return r.Amount.Sub(r.FeeAmount)
+}
+
+// DoCapture captures an authorized payment. Use a completeType of "NotComplete"
+// to leave the remainder of the authorization open for further captures.
+func (pClient *PayPalClient) DoCapture(authorizationId string, amount float64, currencyCode, completeType, invnum, note string) (*CaptureResponse, error) {
+	return pClient.DoCaptureCtx(context.Background(), authorizationId, amount, currencyCode, completeType, invnum, note)
+}
+
+func (pClient *PayPalClient) DoCaptureCtx(ctx context.Context, authorizationId string, amount float64, currencyCode, completeType, invnum, note string) (*CaptureResponse, error) {
+	if completeType != "Complete" && completeType != "NotComplete" {
+		return nil, errors.New("paypal: invalid capture complete type " + completeType)
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "DoCapture")
+	values.Add("AUTHORIZATIONID", authorizationId)
+	values.Add("AMT", fmt.Sprintf("%.2f", amount))
+	values.Add("CURRENCYCODE", currencyCode)
+	values.Add("COMPLETETYPE", completeType)
+	if len(invnum) != 0 {
+		values.Add("INVNUM", invnum)
+	}
+	if len(note) != 0 {
+		values.Add("NOTE", note)
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return newCaptureResponse(response), err
+}
+
+type VoidResponse struct {
+	*PayPalResponse
+	AuthorizationId string
+}
+
+// DoVoid voids an authorization or an order. The authorizationId may be either
+// an authorization ID or the transaction ID of an order.
+func (pClient *PayPalClient) DoVoid(authorizationId, note string) (*VoidResponse, error) {
+	return pClient.DoVoidCtx(context.Background(), authorizationId, note)
+}
+
+func (pClient *PayPalClient) DoVoidCtx(ctx context.Context, authorizationId, note string) (*VoidResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "DoVoid")
+	values.Add("AUTHORIZATIONID", authorizationId)
+	if len(note) != 0 {
+		values.Add("NOTE", note)
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &VoidResponse{response, response.Values.Get("AUTHORIZATIONID")}, err
+}
+
+type AuthorizationResponse struct {
+	*PayPalResponse
+	AuthorizationId       string
+	PaymentStatus         PaymentStatus
+	PendingReason         PendingReason
+	ProtectionEligibility ProtectionEligibility
+	MsgSubId              string
+	Amount                Money
+}
+
+func newAuthorizationResponse(r *PayPalResponse, idKey string) *AuthorizationResponse {
+	return &AuthorizationResponse{
+		PayPalResponse:        r,
+		AuthorizationId:       r.Values.Get(idKey),
+		PaymentStatus:         PaymentStatus(r.Values.Get("PAYMENTSTATUS")),
+		PendingReason:         PendingReason(r.Values.Get("PENDINGREASON")),
+		ProtectionEligibility: parseProtectionEligibility(r.Values, ""),
+		MsgSubId:              r.Values.Get("MSGSUBID"),
+		Amount:                parseMoney(r.Values, "AMT", r.Values.Get("CURRENCYCODE")),
+	}
+}
+
+// DoAuthorization authorizes part or all of an order created with the "Order"
+// payment action. A non-empty msgSubId makes the call safe to retry: PayPal
+// returns the original result instead of authorizing twice.
+func (pClient *PayPalClient) DoAuthorization(orderId string, amount float64, currencyCode, msgSubId string) (*AuthorizationResponse, error) {
+	return pClient.DoAuthorizationCtx(context.Background(), orderId, amount, currencyCode, msgSubId)
+}
+
+func (pClient *PayPalClient) DoAuthorizationCtx(ctx context.Context, orderId string, amount float64, currencyCode, msgSubId string) (*AuthorizationResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "DoAuthorization")
+	values.Add("TRANSACTIONID", orderId)
+	values.Add("TRANSACTIONENTITY", "Order")
+	values.Add("AMT", fmt.Sprintf("%.2f", amount))
+	values.Add("CURRENCYCODE", currencyCode)
+	if len(msgSubId) != 0 {
+		values.Add("MSGSUBID", msgSubId)
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return newAuthorizationResponse(response, "TRANSACTIONID"), err
+}
+
+// DoReauthorization refreshes an authorization once its honor period has
+// expired. The returned AuthorizationId replaces the original one for captures.
+func (pClient *PayPalClient) DoReauthorization(authorizationId string, amount float64, currencyCode string) (*AuthorizationResponse, error) {
+	return pClient.DoReauthorizationCtx(context.Background(), authorizationId, amount, currencyCode)
+}
+
+func (pClient *PayPalClient) DoReauthorizationCtx(ctx context.Context, authorizationId string, amount float64, currencyCode string) (*AuthorizationResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "DoReauthorization")
+	values.Add("AUTHORIZATIONID", authorizationId)
+	values.Add("AMT", fmt.Sprintf("%.2f", amount))
+	values.Add("CURRENCYCODE", currencyCode)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return newAuthorizationResponse(response, "AUTHORIZATIONID"), err
+}
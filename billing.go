@@ -0,0 +1,343 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+type BillingType string
+
+const (
+	RecurringPayments        BillingType = "RecurringPayments"
+	MerchantInitiatedBilling BillingType = "MerchantInitiatedBilling"
+	// MerchantInitiatedBillingSingleAgreement locks the funding source the
+	// buyer picks during checkout to the agreement, instead of letting PayPal
+	// choose one on each reference transaction.
+	MerchantInitiatedBillingSingleAgreement BillingType = "MerchantInitiatedBillingSingleAgreement"
+)
+
+// BillingAgreement requests a billing agreement alongside an express checkout.
+// Use a Type of "RecurringPayments" to obtain a token that can be passed to
+// CreateRecurringPaymentsProfile; the Description must then match the
+// profile's Description.
+type BillingAgreement struct {
+	Type        BillingType
+	Description string
+	PaymentType string
+	Custom      string
+}
+
+func addBillingAgreements(values url.Values, agreements []BillingAgreement) {
+	for i, agreement := range agreements {
+		values.Add(fmt.Sprintf("%s%d", "L_BILLINGTYPE", i), string(agreement.Type))
+		values.Add(fmt.Sprintf("%s%d", "L_BILLINGAGREEMENTDESCRIPTION", i), agreement.Description)
+		if len(agreement.PaymentType) != 0 {
+			values.Add(fmt.Sprintf("%s%d", "L_PAYMENTTYPE", i), agreement.PaymentType)
+		}
+		if len(agreement.Custom) != 0 {
+			values.Add(fmt.Sprintf("%s%d", "L_BILLINGAGREEMENTCUSTOM", i), agreement.Custom)
+		}
+	}
+}
+
+type BillingAgreementCustomerDetails struct {
+	*PayPalResponse
+	Payer         Payer
+	ShipToAddress Address
+}
+
+// GetBillingAgreementCustomerDetails is the billing agreement counterpart of
+// GetExpressCheckoutDetails, returning the buyer behind a SetCustomerBillingAgreement
+// or billing-agreement checkout token.
+func (pClient *PayPalClient) GetBillingAgreementCustomerDetails(token string) (*BillingAgreementCustomerDetails, error) {
+	return pClient.GetBillingAgreementCustomerDetailsCtx(context.Background(), token)
+}
+
+func (pClient *PayPalClient) GetBillingAgreementCustomerDetailsCtx(ctx context.Context, token string) (*BillingAgreementCustomerDetails, error) {
+	values := url.Values{}
+	values.Set("METHOD", "GetBillingAgreementCustomerDetails")
+	values.Add("TOKEN", token)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+
+	payer := parsePayer(response.Values)
+	if len(payer.Business) == 0 {
+		payer.Business = response.Values.Get("PAYERBUSINESS")
+	}
+	return &BillingAgreementCustomerDetails{
+		PayPalResponse: response,
+		Payer:          payer,
+		ShipToAddress:  parseAddress(response.Values, ""),
+	}, err
+}
+
+// BillingAgreementCheckoutOptions configures SetExpressCheckoutBillingAgreement.
+// BillingType defaults to MerchantInitiatedBilling.
+type BillingAgreementCheckoutOptions struct {
+	BillingType   BillingType
+	Description   string
+	PaymentType   string
+	Custom        string
+	CurrencyCode  string
+	InvoiceNumber string
+}
+
+type BillingAgreementCheckoutResponse struct {
+	*PayPalResponse
+	Token string
+}
+
+// SetExpressCheckoutBillingAgreement starts a checkout that only establishes a
+// merchant initiated billing agreement, without charging the buyer. Once the
+// buyer returns, pass the token to CreateBillingAgreement instead of
+// completing a payment.
+func (pClient *PayPalClient) SetExpressCheckoutBillingAgreement(returnURL, cancelURL string, options BillingAgreementCheckoutOptions) (*BillingAgreementCheckoutResponse, error) {
+	return pClient.SetExpressCheckoutBillingAgreementCtx(context.Background(), returnURL, cancelURL, options)
+}
+
+func (pClient *PayPalClient) SetExpressCheckoutBillingAgreementCtx(ctx context.Context, returnURL, cancelURL string, options BillingAgreementCheckoutOptions) (*BillingAgreementCheckoutResponse, error) {
+	if len(options.Description) == 0 {
+		return nil, errors.New("paypal: billing agreements require a description")
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "SetExpressCheckout")
+	values.Add("PAYMENTREQUEST_0_AMT", "0.00")
+	if len(options.CurrencyCode) != 0 {
+		values.Add("PAYMENTREQUEST_0_CURRENCYCODE", options.CurrencyCode)
+	}
+	if len(options.InvoiceNumber) != 0 {
+		values.Add("PAYMENTREQUEST_0_INVNUM", options.InvoiceNumber)
+	}
+	values.Add("RETURNURL", returnURL)
+	values.Add("CANCELURL", cancelURL)
+	values.Add("NOSHIPPING", "1")
+	billingType := options.BillingType
+	if len(billingType) == 0 {
+		billingType = MerchantInitiatedBilling
+	}
+	if billingType != MerchantInitiatedBilling && billingType != MerchantInitiatedBillingSingleAgreement {
+		return nil, errors.New("paypal: invalid billing agreement type " + string(billingType))
+	}
+	addBillingAgreements(values, []BillingAgreement{{
+		Type:        billingType,
+		Description: options.Description,
+		PaymentType: options.PaymentType,
+		Custom:      options.Custom,
+	}})
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &BillingAgreementCheckoutResponse{response, response.Values.Get("TOKEN")}, err
+}
+
+// BillingAgreementAccepted reports whether the buyer agreed to the billing
+// agreement requested for token. The buyer can return to the merchant without
+// accepting it, in which case CreateBillingAgreement would fail.
+func (pClient *PayPalClient) BillingAgreementAccepted(token string) (bool, error) {
+	return pClient.BillingAgreementAcceptedCtx(context.Background(), token)
+}
+
+func (pClient *PayPalClient) BillingAgreementAcceptedCtx(ctx context.Context, token string) (bool, error) {
+	response, err := pClient.GetExpressCheckoutDetailsCtx(ctx, token)
+	if err != nil {
+		return false, err
+	}
+	return response.Values.Get("BILLINGAGREEMENTACCEPTEDSTATUS") == "1", nil
+}
+
+type BillingAgreementResponse struct {
+	*PayPalResponse
+	BillingAgreementId string
+}
+
+// CreateBillingAgreement turns an approved billing agreement checkout token
+// into a billing agreement for later reference transactions.
+func (pClient *PayPalClient) CreateBillingAgreement(token string) (*BillingAgreementResponse, error) {
+	return pClient.CreateBillingAgreementCtx(context.Background(), token)
+}
+
+func (pClient *PayPalClient) CreateBillingAgreementCtx(ctx context.Context, token string) (*BillingAgreementResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "CreateBillingAgreement")
+	values.Add("TOKEN", token)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &BillingAgreementResponse{response, response.Values.Get("BILLINGAGREEMENTID")}, err
+}
+
+type ReferenceTransactionOptions struct {
+	Items          []LineItem
+	InvoiceNumber  string
+	Description    string
+	Custom         string
+	SoftDescriptor string
+	IpAddress      string
+	MsgSubId       string
+
+	// SoftDescriptorCity replaces the merchant phone number shown next to the
+	// soft descriptor on card statements; NotifyUrl overrides the account's
+	// IPN URL for this charge only.
+	SoftDescriptorCity string
+	NotifyUrl          string
+
+	// ShippingAmount is sent as SHIPPINGAMT and, like the item and tax
+	// totals, must be included in the charged amount.
+	ShippingAmount float64
+}
+
+type ReferenceTransactionResponse struct {
+	*PayPalResponse
+	BillingAgreementId string
+	MsgSubId           string
+	AvsCode            AVSResult
+	Cvv2Match          CVVResult
+	PaymentInfo        PaymentInfo
+}
+
+// DoReferenceTransaction charges a stored billing agreement. The action is
+// "Sale" or "Authorization". When items are given their totals are sent as
+// ITEMAMT and TAXAMT, so amount must include them; a mismatch is rejected
+// before the call, as PaymentRequest.Validate does for checkouts.
+func (pClient *PayPalClient) DoReferenceTransaction(billingAgreementId, action string, amount float64, currencyCode string, options ReferenceTransactionOptions) (*ReferenceTransactionResponse, error) {
+	return pClient.DoReferenceTransactionCtx(context.Background(), billingAgreementId, action, amount, currencyCode, options)
+}
+
+func (pClient *PayPalClient) DoReferenceTransactionCtx(ctx context.Context, billingAgreementId, action string, amount float64, currencyCode string, options ReferenceTransactionOptions) (*ReferenceTransactionResponse, error) {
+	if action != "Sale" && action != "Authorization" {
+		return nil, errors.New("paypal: invalid reference transaction action " + action)
+	}
+	request := PaymentRequest{
+		Amount:         amount,
+		Items:          options.Items,
+		ShippingAmount: options.ShippingAmount,
+		SoftDescriptor: options.SoftDescriptor,
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "DoReferenceTransaction")
+	values.Add("REFERENCEID", billingAgreementId)
+	values.Add("PAYMENTACTION", action)
+	values.Add("AMT", fmt.Sprintf("%.2f", amount))
+	values.Add("CURRENCYCODE", currencyCode)
+
+	addLineItems(values, "", options.Items)
+	if request.itemized() {
+		values.Add("ITEMAMT", fmt.Sprintf("%.2f", request.itemAmount()))
+		if taxAmount := request.taxAmount(); taxAmount != 0 {
+			values.Add("TAXAMT", fmt.Sprintf("%.2f", taxAmount))
+		}
+		if options.ShippingAmount != 0 {
+			values.Add("SHIPPINGAMT", fmt.Sprintf("%.2f", options.ShippingAmount))
+		}
+	}
+	optional := map[string]string{
+		"INVNUM":         options.InvoiceNumber,
+		"DESC":           options.Description,
+		"CUSTOM":         options.Custom,
+		"SOFTDESCRIPTOR": options.SoftDescriptor,
+		"IPADDRESS":      options.IpAddress,
+		"MSGSUBID":       options.MsgSubId,
+
+		"SOFTDESCRIPTORCITY": options.SoftDescriptorCity,
+		"NOTIFYURL":          options.NotifyUrl,
+	}
+	for key, value := range optional {
+		if len(value) != 0 {
+			values.Add(key, value)
+		}
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &ReferenceTransactionResponse{
+		PayPalResponse:     response,
+		BillingAgreementId: response.Values.Get("BILLINGAGREEMENTID"),
+		MsgSubId:           response.Values.Get("MSGSUBID"),
+		AvsCode:            AVSResult(response.Values.Get("AVSCODE")),
+		Cvv2Match:          CVVResult(response.Values.Get("CVV2MATCH")),
+		PaymentInfo:        parsePaymentInfo(response.Values, "", response.settleCurrency),
+	}, err
+}
+
+type BillingAgreementStatus string
+
+const (
+	BillingAgreementActive   BillingAgreementStatus = "Active"
+	BillingAgreementCanceled BillingAgreementStatus = "Canceled"
+)
+
+func (s BillingAgreementStatus) String() string {
+	return string(s)
+}
+
+type BillingAgreementDetails struct {
+	*PayPalResponse
+	BillingAgreementId string
+	Description        string
+	Custom             string
+	Status             BillingAgreementStatus
+	Payer              Payer
+}
+
+// BAUpdate views or changes a billing agreement. With an empty status and
+// description it only returns the agreement's current state.
+func (pClient *PayPalClient) BAUpdate(billingAgreementId string, status BillingAgreementStatus, description string) (*BillingAgreementDetails, error) {
+	return pClient.BAUpdateCtx(context.Background(), billingAgreementId, status, description)
+}
+
+func (pClient *PayPalClient) BAUpdateCtx(ctx context.Context, billingAgreementId string, status BillingAgreementStatus, description string) (*BillingAgreementDetails, error) {
+	values := url.Values{}
+	values.Set("METHOD", "BillAgreementUpdate")
+	values.Add("REFERENCEID", billingAgreementId)
+	if len(status) != 0 {
+		values.Add("BILLINGAGREEMENTSTATUS", string(status))
+	}
+	if len(description) != 0 {
+		values.Add("BILLINGAGREEMENTDESCRIPTION", description)
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &BillingAgreementDetails{
+		PayPalResponse:     response,
+		BillingAgreementId: response.Values.Get("BILLINGAGREEMENTID"),
+		Description:        response.Values.Get("BILLINGAGREEMENTDESCRIPTION"),
+		Custom:             response.Values.Get("BILLINGAGREEMENTCUSTOM"),
+		Status:             BillingAgreementStatus(response.Values.Get("BILLINGAGREEMENTSTATUS")),
+		Payer:              parsePayer(response.Values),
+	}, err
+}
+
+func (pClient *PayPalClient) GetBillingAgreement(billingAgreementId string) (*BillingAgreementDetails, error) {
+	return pClient.GetBillingAgreementCtx(context.Background(), billingAgreementId)
+}
+
+func (pClient *PayPalClient) GetBillingAgreementCtx(ctx context.Context, billingAgreementId string) (*BillingAgreementDetails, error) {
+	return pClient.BAUpdateCtx(ctx, billingAgreementId, "", "")
+}
+
+func (pClient *PayPalClient) CancelBillingAgreement(billingAgreementId string) (*BillingAgreementDetails, error) {
+	return pClient.CancelBillingAgreementCtx(context.Background(), billingAgreementId)
+}
+
+func (pClient *PayPalClient) CancelBillingAgreementCtx(ctx context.Context, billingAgreementId string) (*BillingAgreementDetails, error) {
+	return pClient.BAUpdateCtx(ctx, billingAgreementId, BillingAgreementCanceled, "")
+}
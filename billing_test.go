@@ -0,0 +1,40 @@
+package paypal
+
+import "testing"
+
+func TestDoReferenceTransactionTotals(t *testing.T) {
+	pClient, last := newTestClient(t, "ACK=Success&TRANSACTIONID=1AB23456CD789012E")
+	options := ReferenceTransactionOptions{
+		Items:          []LineItem{{Name: "Widget", Quantity: 2, Amount: 5, TaxAmount: 0.5}},
+		ShippingAmount: 3,
+	}
+
+	if _, err := pClient.DoReferenceTransaction("B-1AB23456CD789012E", "Sale", 14, "USD", options); err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, last(), map[string]string{
+		"AMT":         "14.00",
+		"ITEMAMT":     "10.00",
+		"TAXAMT":      "1.00",
+		"SHIPPINGAMT": "3.00",
+	})
+
+	if _, err := pClient.DoReferenceTransaction("B-1AB23456CD789012E", "Sale", 12, "USD", options); err == nil {
+		t.Error("expected an error for an amount that does not match the totals")
+	}
+}
+
+func TestDoReferenceTransactionSoftDescriptor(t *testing.T) {
+	pClient, last := newTestClient(t, "ACK=Success&TRANSACTIONID=1AB23456CD789012E")
+
+	options := ReferenceTransactionOptions{SoftDescriptor: "EXAMPLE*SHOP ORDER"}
+	if _, err := pClient.DoReferenceTransaction("B-1AB23456CD789012E", "Sale", 10, "USD", options); err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, last(), map[string]string{"SOFTDESCRIPTOR": "EXAMPLE*SHOP ORDER"})
+
+	options.SoftDescriptor = "EXAMPLE*SHOP ORDER 12345"
+	if _, err := pClient.DoReferenceTransaction("B-1AB23456CD789012E", "Sale", 10, "USD", options); err == nil {
+		t.Error("expected an error for a soft descriptor over 22 characters")
+	}
+}
@@ -0,0 +1,83 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("paypal: circuit breaker open, PayPal is failing")
+
+// CircuitBreaker fails calls fast with ErrCircuitOpen once threshold calls in
+// a row failed without reaching PayPal or with a 5xx or 429 status. Calls
+// ended by their own context count neither way. After cooldown a single trial
+// call is let through, closing the circuit again if it succeeds.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	trial     bool
+}
+
+// NewCircuitBreaker returns an error unless threshold is at least one and
+// cooldown positive.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, error) {
+	if threshold < 1 {
+		return nil, fmt.Errorf("paypal: invalid circuit breaker threshold %d, it must be at least 1", threshold)
+	}
+	if cooldown <= 0 {
+		return nil, fmt.Errorf("paypal: invalid circuit breaker cooldown %s, it must be positive", cooldown)
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown}, nil
+}
+
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold
+}
+
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.trial || time.Since(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+	b.trial = true
+	return nil
+}
+
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	if !breakerFailure(err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// breakerFailure tells whether err shows PayPal is unreachable or failing:
+// no response at all, a 5xx or a 429. Other statuses are answers.
+func breakerFailure(err error) bool {
+	var tError *TransportError
+	if !errors.As(err, &tError) {
+		return false
+	}
+	return tError.StatusCode == 0 || tError.StatusCode >= 500 || tError.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,134 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingLimiter blocks until ctx is done while block is set.
+type blockingLimiter struct {
+	block atomic.Bool
+}
+
+func (l *blockingLimiter) Wait(ctx context.Context) error {
+	if !l.block.Load() {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCircuitBreakerTrialCanceledInLimiter(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ACK=Success"))
+	}))
+	defer server.Close()
+
+	limiter := &blockingLimiter{}
+	breaker, err := NewCircuitBreaker(1, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pClient := NewClient(SignatureCredentials{"user", "pwd", "sig"}, WithEndpoint(server.URL), WithCircuitBreaker(breaker), WithRateLimit(limiter))
+
+	var tError *TransportError
+	if _, err := pClient.Call(context.Background(), "GetBalance", nil); !errors.As(err, &tError) {
+		t.Fatalf("first call: got %v, want a TransportError", err)
+	}
+	if !breaker.Open() {
+		t.Fatal("breaker not open after a failure at threshold 1")
+	}
+	failing.Store(false)
+	time.Sleep(20 * time.Millisecond)
+
+	limiter.block.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	_, err = pClient.Call(ctx, "GetBalance", nil)
+	cancel()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("trial call: got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	limiter.block.Store(false)
+	response, err := pClient.Call(context.Background(), "GetBalance", nil)
+	if err != nil {
+		t.Fatalf("call after cooldown: %v", err)
+	}
+	if !response.Success() || breaker.Open() {
+		t.Fatalf("breaker still open after a successful trial")
+	}
+}
+
+func TestNewCircuitBreakerInvalid(t *testing.T) {
+	for _, test := range []struct {
+		threshold int
+		cooldown  time.Duration
+	}{
+		{0, time.Second},
+		{-1, time.Second},
+		{1, 0},
+		{1, -time.Second},
+	} {
+		if breaker, err := NewCircuitBreaker(test.threshold, test.cooldown); err == nil {
+			t.Errorf("NewCircuitBreaker(%d, %s) = %+v, want an error", test.threshold, test.cooldown, breaker)
+		}
+	}
+}
+
+func TestCircuitBreakerCountedFailures(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		status int
+		delay  time.Duration
+		open   bool
+	}{
+		{"500", http.StatusInternalServerError, 0, true},
+		{"503", http.StatusServiceUnavailable, 0, true},
+		{"429", http.StatusTooManyRequests, 0, true},
+		{"400", http.StatusBadRequest, 0, false},
+		{"404", http.StatusNotFound, 0, false},
+		{"caller deadline", http.StatusOK, 50 * time.Millisecond, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(test.delay):
+				case <-r.Context().Done():
+					return
+				}
+				w.WriteHeader(test.status)
+				w.Write([]byte("ACK=Success"))
+			}))
+			defer server.Close()
+			breaker, err := NewCircuitBreaker(2, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pClient := NewClient(SignatureCredentials{"user", "pwd", "sig"}, WithEndpoint(server.URL), WithCircuitBreaker(breaker))
+
+			timeout := time.Second
+			if test.delay != 0 {
+				timeout = test.delay / 2
+			}
+			for i := 0; i < 2; i++ {
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				pClient.Call(ctx, "GetBalance", nil)
+				cancel()
+			}
+			if breaker.Open() != test.open {
+				t.Errorf("Open() = %t, want %t", breaker.Open(), test.open)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type ButtonVariable struct {
+	Name  string
+	Value string
+}
+
+type ButtonOptionSelection struct {
+	Value string
+	Price float64
+}
+
+type ButtonOption struct {
+	Name       string
+	Selections []ButtonOptionSelection
+}
+
+// Button describes a hosted, encrypted or clear text payment button. Build one
+// with NewButton and the Add methods rather than writing L_BUTTONVARn strings
+// by hand.
+type Button struct {
+	Code          string
+	Type          string
+	SubType       string
+	Country       string
+	Language      string
+	Image         string
+	ImageUrl      string
+	BuyNowText    string
+	SubscribeText string
+	Variables     []ButtonVariable
+	Options       []ButtonOption
+	TextBoxes     []string
+}
+
+func NewButton(code, buttonType string) *Button {
+	return &Button{Code: code, Type: buttonType}
+}
+
+func (b *Button) AddVariable(name, value string) *Button {
+	b.Variables = append(b.Variables, ButtonVariable{name, value})
+	return b
+}
+
+func (b *Button) AddAmount(name string, amount float64) *Button {
+	return b.AddVariable(name, fmt.Sprintf("%.2f", amount))
+}
+
+// AddOption adds a drop-down menu to the button. PayPal only accepts prices on
+// the selections of the first option.
+func (b *Button) AddOption(name string, selections ...ButtonOptionSelection) *Button {
+	b.Options = append(b.Options, ButtonOption{name, selections})
+	return b
+}
+
+func (b *Button) AddTextBox(name string) *Button {
+	b.TextBoxes = append(b.TextBoxes, name)
+	return b
+}
+
+func (b *Button) addValues(values url.Values) error {
+	if len(b.Code) == 0 || len(b.Type) == 0 {
+		return errors.New("paypal: button code and type are required")
+	}
+
+	values.Add("BUTTONCODE", b.Code)
+	values.Add("BUTTONTYPE", b.Type)
+	optional := map[string]string{
+		"BUTTONSUBTYPE":  b.SubType,
+		"BUTTONCOUNTRY":  b.Country,
+		"BUTTONLANGUAGE": b.Language,
+		"BUTTONIMAGE":    b.Image,
+		"BUTTONIMAGEURL": b.ImageUrl,
+		"BUYNOWTEXT":     b.BuyNowText,
+		"SUBSCRIBETEXT":  b.SubscribeText,
+	}
+	for key, value := range optional {
+		if len(value) != 0 {
+			values.Add(key, value)
+		}
+	}
+
+	for i, variable := range b.Variables {
+		if len(variable.Name) == 0 || strings.Contains(variable.Name, "=") {
+			return errors.New("paypal: invalid button variable name " + variable.Name)
+		}
+		values.Add(fmt.Sprintf("%s%d", "L_BUTTONVAR", i), variable.Name+"="+variable.Value)
+	}
+	for i, option := range b.Options {
+		values.Add(fmt.Sprintf("OPTION%dNAME", i), option.Name)
+		for j, selection := range option.Selections {
+			values.Add(fmt.Sprintf("L_OPTION%dSELECT%d", i, j), selection.Value)
+			if selection.Price == 0 {
+				continue
+			}
+			if i != 0 {
+				return errors.New("paypal: only the first button option may carry prices")
+			}
+			values.Add(fmt.Sprintf("L_OPTION%dPRICE%d", i, j), fmt.Sprintf("%.2f", selection.Price))
+		}
+	}
+	for i, textBox := range b.TextBoxes {
+		values.Add(fmt.Sprintf("%s%d", "L_TEXTBOX", i), textBox)
+	}
+
+	return nil
+}
+
+func parseButton(values url.Values) Button {
+	button := Button{
+		Code:          values.Get("BUTTONCODE"),
+		Type:          values.Get("BUTTONTYPE"),
+		SubType:       values.Get("BUTTONSUBTYPE"),
+		Country:       values.Get("BUTTONCOUNTRY"),
+		Language:      values.Get("BUTTONLANGUAGE"),
+		Image:         values.Get("BUTTONIMAGE"),
+		ImageUrl:      values.Get("BUTTONIMAGEURL"),
+		BuyNowText:    values.Get("BUYNOWTEXT"),
+		SubscribeText: values.Get("SUBSCRIBETEXT"),
+	}
+
+	for i := 0; ; i++ {
+		variable, ok := values[fmt.Sprintf("%s%d", "L_BUTTONVAR", i)]
+		if !ok {
+			break
+		}
+		pair := strings.SplitN(strings.Trim(variable[0], `"`), "=", 2)
+		if len(pair) == 1 {
+			pair = append(pair, "")
+		}
+		button.Variables = append(button.Variables, ButtonVariable{pair[0], pair[1]})
+	}
+	for i := 0; ; i++ {
+		name, ok := values[fmt.Sprintf("OPTION%dNAME", i)]
+		if !ok {
+			break
+		}
+		option := ButtonOption{Name: name[0]}
+		for j := 0; ; j++ {
+			selection, ok := values[fmt.Sprintf("L_OPTION%dSELECT%d", i, j)]
+			if !ok {
+				break
+			}
+			option.Selections = append(option.Selections, ButtonOptionSelection{
+				Value: selection[0],
+				Price: parseAmount(values, fmt.Sprintf("L_OPTION%dPRICE%d", i, j)),
+			})
+		}
+		button.Options = append(button.Options, option)
+	}
+	for i := 0; ; i++ {
+		textBox, ok := values[fmt.Sprintf("%s%d", "L_TEXTBOX", i)]
+		if !ok {
+			break
+		}
+		button.TextBoxes = append(button.TextBoxes, textBox[0])
+	}
+
+	return button
+}
+
+type ButtonResponse struct {
+	*PayPalResponse
+	HostedButtonId string
+	WebsiteCode    string
+	EmailLink      string
+}
+
+func newButtonResponse(r *PayPalResponse) *ButtonResponse {
+	return &ButtonResponse{
+		PayPalResponse: r,
+		HostedButtonId: r.Values.Get("HOSTEDBUTTONID"),
+		WebsiteCode:    r.Values.Get("WEBSITECODE"),
+		EmailLink:      r.Values.Get("EMAILLINK"),
+	}
+}
+
+type ButtonDetailsResponse struct {
+	*ButtonResponse
+	Button Button
+}
+
+type ButtonSearchResult struct {
+	HostedButtonId string
+	ButtonType     string
+	ItemName       string
+	ModifyDate     time.Time
+}
+
+type ButtonSearchResponse struct {
+	*PayPalResponse
+	Results []ButtonSearchResult
+}
+
+func (pClient *PayPalClient) BMCreateButton(button *Button) (*ButtonResponse, error) {
+	return pClient.BMCreateButtonCtx(context.Background(), button)
+}
+
+func (pClient *PayPalClient) BMCreateButtonCtx(ctx context.Context, button *Button) (*ButtonResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "BMCreateButton")
+	if err := button.addValues(values); err != nil {
+		return nil, err
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return newButtonResponse(response), err
+}
+
+// BMUpdateButton replaces every field of a hosted button; fields missing from
+// button are cleared rather than left unchanged.
+func (pClient *PayPalClient) BMUpdateButton(hostedButtonId string, button *Button) (*ButtonResponse, error) {
+	return pClient.BMUpdateButtonCtx(context.Background(), hostedButtonId, button)
+}
+
+func (pClient *PayPalClient) BMUpdateButtonCtx(ctx context.Context, hostedButtonId string, button *Button) (*ButtonResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "BMUpdateButton")
+	values.Add("HOSTEDBUTTONID", hostedButtonId)
+	if err := button.addValues(values); err != nil {
+		return nil, err
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return newButtonResponse(response), err
+}
+
+func (pClient *PayPalClient) BMGetButtonDetails(hostedButtonId string) (*ButtonDetailsResponse, error) {
+	return pClient.BMGetButtonDetailsCtx(context.Background(), hostedButtonId)
+}
+
+func (pClient *PayPalClient) BMGetButtonDetailsCtx(ctx context.Context, hostedButtonId string) (*ButtonDetailsResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "BMGetButtonDetails")
+	values.Add("HOSTEDBUTTONID", hostedButtonId)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &ButtonDetailsResponse{newButtonResponse(response), parseButton(response.Values)}, err
+}
+
+func (pClient *PayPalClient) BMButtonSearch(startDate, endDate time.Time) (*ButtonSearchResponse, error) {
+	return pClient.BMButtonSearchCtx(context.Background(), startDate, endDate)
+}
+
+func (pClient *PayPalClient) BMButtonSearchCtx(ctx context.Context, startDate, endDate time.Time) (*ButtonSearchResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "BMButtonSearch")
+	values.Add("STARTDATE", startDate.UTC().Format(nvpDateLayout))
+	if !endDate.IsZero() {
+		values.Add("ENDDATE", endDate.UTC().Format(nvpDateLayout))
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+
+	search := &ButtonSearchResponse{PayPalResponse: response}
+	for i := 0; ; i++ {
+		id, ok := response.Values[fmt.Sprintf("%s%d", "L_HOSTEDBUTTONID", i)]
+		if !ok {
+			break
+		}
+		search.Results = append(search.Results, ButtonSearchResult{
+			HostedButtonId: id[0],
+			ButtonType:     response.Values.Get(fmt.Sprintf("%s%d", "L_BUTTONTYPE", i)),
+			ItemName:       response.Values.Get(fmt.Sprintf("%s%d", "L_ITEMNAME", i)),
+			ModifyDate:     parseTime(response.Values, fmt.Sprintf("%s%d", "L_MODIFYDATE", i)),
+		})
+	}
+	return search, err
+}
+
+// BMManageButtonStatus changes the status of a hosted button. PayPal currently
+// only supports the "DELETE" status.
+func (pClient *PayPalClient) BMManageButtonStatus(hostedButtonId, buttonStatus string) (*PayPalResponse, error) {
+	return pClient.BMManageButtonStatusCtx(context.Background(), hostedButtonId, buttonStatus)
+}
+
+func (pClient *PayPalClient) BMManageButtonStatusCtx(ctx context.Context, hostedButtonId, buttonStatus string) (*PayPalResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "BMManageButtonStatus")
+	values.Add("HOSTEDBUTTONID", hostedButtonId)
+	values.Add("BUTTONSTATUS", buttonStatus)
+	return pClient.PerformRequestCtx(ctx, values)
+}
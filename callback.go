@@ -0,0 +1,143 @@
+package paypal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const CALLBACK_VERSION = "61.0"
+
+type ShippingOption struct {
+	Name            string
+	Label           string
+	Amount          float64
+	TaxAmount       float64
+	InsuranceAmount float64
+	IsDefault       bool
+}
+
+func validateShippingOptions(options []ShippingOption) error {
+	defaults := 0
+	for _, option := range options {
+		if option.IsDefault {
+			defaults++
+		}
+	}
+	if defaults != 1 {
+		return errors.New("paypal: exactly one shipping option must be the default")
+	}
+	return nil
+}
+
+func addShippingOptions(values url.Values, options []ShippingOption) {
+	for i, option := range options {
+		values.Add(fmt.Sprintf("%s%d", "L_SHIPPINGOPTIONNAME", i), option.Name)
+		if len(option.Label) != 0 {
+			values.Add(fmt.Sprintf("%s%d", "L_SHIPPINGOPTIONLABEL", i), option.Label)
+		}
+		values.Add(fmt.Sprintf("%s%d", "L_SHIPPINGOPTIONAMOUNT", i), fmt.Sprintf("%.2f", option.Amount))
+		values.Add(fmt.Sprintf("%s%d", "L_SHIPPINGOPTIONISDEFAULT", i), fmt.Sprintf("%t", option.IsDefault))
+	}
+}
+
+type CallbackRequest struct {
+	Token           string
+	CallbackVersion string
+	CurrencyCode    string
+	LocaleCode      string
+	Items           []ItemDetails
+	ShipToAddress   Address
+	Values          url.Values
+}
+
+// RateCalculator returns the shipping options offered for the buyer's address.
+// Returning no options, or an error, tells PayPal the address cannot be shipped
+// to. Exactly one of the returned options must be the default.
+type RateCalculator func(request *CallbackRequest) ([]ShippingOption, error)
+
+// CallbackHandler serves PayPal's Instant Update callback, answering the
+// CallbackRequest PayPal posts when the buyer picks a shipping address.
+type CallbackHandler struct {
+	Calculator     RateCalculator
+	OfferInsurance bool
+}
+
+func NewCallbackHandler(calculator RateCalculator) *CallbackHandler {
+	return &CallbackHandler{Calculator: calculator}
+}
+
+func ParseCallbackRequest(values url.Values) *CallbackRequest {
+	address := parseAddress(values, "")
+	address.CountryCode = normalizeCountryCode(values.Get("SHIPTOCOUNTRY"))
+	return &CallbackRequest{
+		Token:           values.Get("TOKEN"),
+		CallbackVersion: values.Get("CALLBACKVERSION"),
+		CurrencyCode:    values.Get("CURRENCYCODE"),
+		LocaleCode:      values.Get("LOCALECODE"),
+		Items:           parseLineItems(values, "", values.Get("CURRENCYCODE")),
+		ShipToAddress:   address,
+		Values:          values,
+	}
+}
+
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<16)
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("METHOD") != "CallbackRequest" {
+		http.Error(w, "invalid callback request", http.StatusBadRequest)
+		return
+	}
+
+	request := ParseCallbackRequest(r.PostForm)
+	options, err := h.Calculator(request)
+	if err != nil {
+		options = nil
+	}
+
+	response, err := h.encodeResponse(request, options)
+	if err != nil {
+		// PayPal falls back to the flat-rate options of SetExpressCheckout.
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(response.Encode()))
+}
+
+func (h *CallbackHandler) encodeResponse(request *CallbackRequest, options []ShippingOption) (url.Values, error) {
+	values := url.Values{}
+	values.Set("METHOD", "CallbackResponse")
+	callbackVersion := request.CallbackVersion
+	if len(callbackVersion) == 0 {
+		callbackVersion = CALLBACK_VERSION
+	}
+	values.Add("CALLBACKVERSION", callbackVersion)
+	values.Add("CURRENCYCODE", request.CurrencyCode)
+
+	if len(options) == 0 {
+		values.Add("NO_SHIPPING_OPTION_DETAILS", "1")
+		return values, nil
+	}
+	if err := validateShippingOptions(options); err != nil {
+		return nil, err
+	}
+
+	if h.OfferInsurance {
+		values.Add("OFFERINSURANCEOPTION", "true")
+	} else {
+		values.Add("OFFERINSURANCEOPTION", "false")
+	}
+	addShippingOptions(values, options)
+	for i, option := range options {
+		values.Add(fmt.Sprintf("%s%d", "L_TAXAMT", i), fmt.Sprintf("%.2f", option.TaxAmount))
+		if h.OfferInsurance {
+			values.Add(fmt.Sprintf("%s%d", "L_INSURANCEAMOUNT", i), fmt.Sprintf("%.2f", option.InsuranceAmount))
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,64 @@
+package paypal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func serveCallback(t *testing.T, handler *CallbackHandler, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestParseCallbackRequestCountryCode(t *testing.T) {
+	request := ParseCallbackRequest(url.Values{"SHIPTOCOUNTRY": {" uk"}})
+	if request.ShipToAddress.CountryCode != "GB" {
+		t.Errorf("CountryCode = %q, want %q", request.ShipToAddress.CountryCode, "GB")
+	}
+}
+
+func TestCallbackHandlerDefaultOption(t *testing.T) {
+	form := url.Values{"METHOD": {"CallbackRequest"}, "CURRENCYCODE": {"USD"}, "SHIPTOCOUNTRY": {"US"}}
+	tests := []struct {
+		name     string
+		defaults []bool
+		status   int
+	}{
+		{"one default", []bool{false, true}, http.StatusOK},
+		{"no default", []bool{false, false}, http.StatusInternalServerError},
+		{"two defaults", []bool{true, true}, http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewCallbackHandler(func(request *CallbackRequest) ([]ShippingOption, error) {
+				var options []ShippingOption
+				for _, isDefault := range test.defaults {
+					options = append(options, ShippingOption{Name: "Ground", Amount: 5, IsDefault: isDefault})
+				}
+				return options, nil
+			})
+			w := serveCallback(t, handler, form)
+			if w.Code != test.status {
+				t.Fatalf("status = %d, want %d", w.Code, test.status)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			values, err := url.ParseQuery(w.Body.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkValues(t, values, map[string]string{
+				"METHOD":                     "CallbackResponse",
+				"L_SHIPPINGOPTIONISDEFAULT1": "true",
+			})
+		})
+	}
+}
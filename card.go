@@ -0,0 +1,181 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type CreditCard struct {
+	Type           string
+	Number         string
+	ExpiryMonth    int
+	ExpiryYear     int
+	CVV2           string
+	StartMonth     int
+	StartYear      int
+	IssueNumber    string
+	FirstName      string
+	LastName       string
+	Email          string
+	BillingAddress Address
+}
+
+var creditCardTypes = map[string]bool{
+	"Visa":       true,
+	"MasterCard": true,
+	"Discover":   true,
+	"Amex":       true,
+	"Maestro":    true,
+}
+
+// Validate performs the checks PayPal would otherwise only report after a
+// round trip: a known card type, a Luhn-valid number, an unexpired expiry date,
+// a CVV2 of the right length and the billing fields PayPal requires.
+func (c *CreditCard) Validate() error {
+	if !creditCardTypes[c.Type] {
+		return errors.New("paypal: unsupported credit card type " + c.Type)
+	}
+	if !validCardNumber(c.Number) {
+		return errors.New("paypal: invalid credit card number")
+	}
+	if c.ExpiryMonth < 1 || c.ExpiryMonth > 12 || c.ExpiryYear < 1000 || c.ExpiryYear > 9999 {
+		return errors.New("paypal: invalid credit card expiry date")
+	}
+	if !time.Date(c.ExpiryYear, time.Month(c.ExpiryMonth)+1, 1, 0, 0, 0, 0, time.UTC).After(time.Now()) {
+		return errors.New("paypal: credit card has expired")
+	}
+	if len(c.CVV2) != 0 {
+		length := 3
+		if c.Type == "Amex" {
+			length = 4
+		}
+		if len(c.CVV2) != length || !isDigits(c.CVV2) {
+			return fmt.Errorf("paypal: %s CVV2 must be %d digits", c.Type, length)
+		}
+	}
+	if c.Type == "Maestro" && len(c.IssueNumber) == 0 && c.StartMonth == 0 {
+		return errors.New("paypal: Maestro cards require an issue number or start date")
+	}
+	if len(c.FirstName) == 0 || len(c.LastName) == 0 {
+		return errors.New("paypal: credit card holder first and last name are required")
+	}
+
+	address := c.BillingAddress
+	if len(address.Street) == 0 || len(address.City) == 0 || len(address.State) == 0 || len(address.Zip) == 0 {
+		return errors.New("paypal: credit card billing street, city, state and zip are required")
+	}
+	if len(normalizeCountryCode(address.CountryCode)) != 2 {
+		return errors.New("paypal: credit card billing country code must be a two letter ISO code")
+	}
+
+	return nil
+}
+
+func (c *CreditCard) addValues(values url.Values) {
+	values.Add("CREDITCARDTYPE", c.Type)
+	values.Add("ACCT", c.Number)
+	values.Add("EXPDATE", fmt.Sprintf("%02d%04d", c.ExpiryMonth, c.ExpiryYear))
+	if len(c.CVV2) != 0 {
+		values.Add("CVV2", c.CVV2)
+	}
+	if c.StartMonth != 0 {
+		values.Add("STARTDATE", fmt.Sprintf("%02d%04d", c.StartMonth, c.StartYear))
+	}
+	if len(c.IssueNumber) != 0 {
+		values.Add("ISSUENUMBER", c.IssueNumber)
+	}
+	values.Add("FIRSTNAME", c.FirstName)
+	values.Add("LASTNAME", c.LastName)
+	if len(c.Email) != 0 {
+		values.Add("EMAIL", c.Email)
+	}
+
+	address := c.BillingAddress
+	values.Add("STREET", address.Street)
+	if len(address.Street2) != 0 {
+		values.Add("STREET2", address.Street2)
+	}
+	values.Add("CITY", address.City)
+	values.Add("STATE", address.State)
+	values.Add("ZIP", address.Zip)
+	values.Add("COUNTRYCODE", normalizeCountryCode(address.CountryCode))
+	if len(address.Phone) != 0 {
+		values.Add("PHONENUM", address.Phone)
+	}
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return len(s) != 0
+}
+
+func validCardNumber(number string) bool {
+	if len(number) < 12 || len(number) > 19 || !isDigits(number) {
+		return false
+	}
+
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if (len(number)-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+type CreditResponse struct {
+	*PayPalResponse
+	TransactionId string
+	CurrencyCode  string
+}
+
+// DoNonReferencedCredit credits a card without an original transaction to
+// refund against. It is only available to Website Payments Pro accounts with
+// the feature enabled.
+func (pClient *PayPalClient) DoNonReferencedCredit(card CreditCard, amount float64, currencyCode, note string) (*CreditResponse, error) {
+	return pClient.DoNonReferencedCreditCtx(context.Background(), card, amount, currencyCode, note)
+}
+
+func (pClient *PayPalClient) DoNonReferencedCreditCtx(ctx context.Context, card CreditCard, amount float64, currencyCode, note string) (*CreditResponse, error) {
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, errors.New("paypal: credit amount must be positive")
+	}
+	if len(currencyCode) != 3 || strings.ToUpper(currencyCode) != currencyCode {
+		return nil, errors.New("paypal: invalid currency code " + currencyCode)
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "DoNonReferencedCredit")
+	values.Add("AMT", fmt.Sprintf("%.2f", amount))
+	values.Add("CURRENCYCODE", currencyCode)
+	if len(note) != 0 {
+		values.Add("NOTE", note)
+	}
+	card.addValues(values)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &CreditResponse{
+		PayPalResponse: response,
+		TransactionId:  response.Values.Get("TRANSACTIONID"),
+		CurrencyCode:   response.Values.Get("CURRENCYCODE"),
+	}, err
+}
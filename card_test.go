@@ -0,0 +1,48 @@
+package paypal
+
+import (
+	"net/url"
+	"testing"
+)
+
+func newTestCard() CreditCard {
+	return CreditCard{
+		Type: "Visa", Number: "4111111111111111", ExpiryMonth: 3, ExpiryYear: 2030, CVV2: "123",
+		FirstName: "Ada", LastName: "Lovelace",
+		BillingAddress: Address{Street: "1 Main St", City: "San Jose", State: "CA", Zip: "95131", CountryCode: "us", Phone: "408-555-0100"},
+	}
+}
+
+func TestCreditCardBillingValues(t *testing.T) {
+	card := newTestCard()
+	values := url.Values{}
+	card.addValues(values)
+	checkValues(t, values, map[string]string{
+		"EXPDATE":     "032030",
+		"COUNTRYCODE": "US",
+		"PHONENUM":    "408-555-0100",
+	})
+	if _, ok := values["SHIPTOPHONENUM"]; ok {
+		t.Error("billing phone sent as SHIPTOPHONENUM")
+	}
+}
+
+func TestCreditCardValidateCountryCode(t *testing.T) {
+	tests := []struct {
+		countryCode string
+		valid       bool
+	}{
+		{"US", true},
+		{"uk", true},
+		{" GB ", true},
+		{"USA", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		card := newTestCard()
+		card.BillingAddress.CountryCode = test.countryCode
+		if err := card.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate() with country %q = %v, want valid %t", test.countryCode, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,720 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ShippingDisplay int
+
+const (
+	ShippingOptional ShippingDisplay = iota
+	ShippingHidden
+	ShippingRequired
+)
+
+// SolutionType selects whether buyers without a PayPal account may pay
+// (SolutionSole) or must log in (SolutionMark, PayPal's default).
+type SolutionType string
+
+const (
+	SolutionSole SolutionType = "Sole"
+	SolutionMark SolutionType = "Mark"
+)
+
+// LandingPage selects whether the card form (LandingBilling) or the PayPal
+// login (LandingLogin) is shown first. It only applies with SolutionSole.
+type LandingPage string
+
+const (
+	LandingBilling LandingPage = "Billing"
+	LandingLogin   LandingPage = "Login"
+)
+
+// CheckoutStatus tells how far a checkout got. Only a checkout whose payment
+// was not initiated yet can be completed with DoExpressCheckout; one that is
+// in progress should be looked up again later rather than retried.
+type CheckoutStatus string
+
+const (
+	CheckoutNotInitiated CheckoutStatus = "PaymentActionNotInitiated"
+	CheckoutFailed       CheckoutStatus = "PaymentActionFailed"
+	CheckoutInProgress   CheckoutStatus = "PaymentActionInProgress"
+	CheckoutCompleted    CheckoutStatus = "PaymentActionCompleted"
+)
+
+func (s CheckoutStatus) String() string {
+	return string(s)
+}
+
+// PaymentRequest is a single PAYMENTREQUEST_n payment. When it itemizes the
+// order, through Items or any of the breakdown amounts, Amount must equal the
+// sum of ItemAmount, TaxAmount, ShippingAmount, HandlingAmount,
+// InsuranceAmount and ShippingDiscount, the latter being zero or negative.
+// ItemAmount and TaxAmount default to the totals of Items; when items carry
+// their own tax, TaxAmount must be left zero or match their total.
+// ItemCategory is the category of items that do not set their own, so a mixed
+// cart can default to one category and override it per item. NotifyUrl
+// overrides the account's IPN URL for notifications about this payment.
+// Custom is passed through to IPN messages and transaction details, while
+// SoftDescriptor replaces the merchant name on the buyer's card statement.
+type PaymentRequest struct {
+	PaymentRequestId string
+	SellerAccountId  string
+	Amount           float64
+	CurrencyCode     string
+	PaymentAction    string
+	InvoiceNumber    string
+	Description      string
+	Items            []LineItem
+	ItemCategory     string
+	ItemAmount       float64
+	TaxAmount        float64
+	ShippingAmount   float64
+	HandlingAmount   float64
+	InsuranceAmount  float64
+	ShippingDiscount float64
+	ShipToAddress    *Address
+	NotifyUrl        string
+	Custom           string
+	SoftDescriptor   string
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func (p *PaymentRequest) itemAmount() float64 {
+	if p.ItemAmount != 0 || len(p.Items) == 0 {
+		return p.ItemAmount
+	}
+	var itemAmount float64
+	for _, item := range p.Items {
+		itemAmount += item.Amount * float64(item.Quantity)
+	}
+	return itemAmount
+}
+
+func (p *PaymentRequest) itemTaxAmount() float64 {
+	var taxAmount float64
+	for _, item := range p.Items {
+		taxAmount += item.TaxAmount * float64(item.Quantity)
+	}
+	return taxAmount
+}
+
+func (p *PaymentRequest) taxAmount() float64 {
+	if p.TaxAmount != 0 {
+		return p.TaxAmount
+	}
+	return p.itemTaxAmount()
+}
+
+func (p *PaymentRequest) itemized() bool {
+	return len(p.Items) != 0 || p.ItemAmount != 0 || p.TaxAmount != 0 || p.ShippingAmount != 0 ||
+		p.HandlingAmount != 0 || p.InsuranceAmount != 0 || p.ShippingDiscount != 0
+}
+
+// maxSoftDescriptorLength is the longest soft descriptor PayPal accepts.
+const maxSoftDescriptorLength = 22
+
+func validateSoftDescriptor(softDescriptor string) error {
+	if len(softDescriptor) > maxSoftDescriptorLength {
+		return fmt.Errorf("paypal: soft descriptor is limited to %d characters", maxSoftDescriptorLength)
+	}
+	return nil
+}
+
+// Validate reports the amount mismatches PayPal rejects with error 10413, as
+// well as soft descriptors that are too long.
+func (p *PaymentRequest) Validate() error {
+	if err := validateSoftDescriptor(p.SoftDescriptor); err != nil {
+		return err
+	}
+	if !p.itemized() {
+		return nil
+	}
+	if p.ShippingDiscount > 0 {
+		return errors.New("paypal: shipping discount must be zero or negative")
+	}
+
+	itemAmount := p.itemAmount()
+	if len(p.Items) != 0 && p.ItemAmount != 0 {
+		var sum float64
+		for _, item := range p.Items {
+			sum += item.Amount * float64(item.Quantity)
+		}
+		if cents(sum) != cents(p.ItemAmount) {
+			return fmt.Errorf("paypal: item amount %.2f does not match the item total %.2f", p.ItemAmount, sum)
+		}
+	}
+
+	if itemTax := p.itemTaxAmount(); itemTax != 0 && p.TaxAmount != 0 && cents(itemTax) != cents(p.TaxAmount) {
+		return fmt.Errorf("paypal: tax amount %.2f does not match the item tax total %.2f", p.TaxAmount, itemTax)
+	}
+
+	total := itemAmount + p.taxAmount() + p.ShippingAmount + p.HandlingAmount + p.InsuranceAmount + p.ShippingDiscount
+	if cents(total) != cents(p.Amount) {
+		return fmt.Errorf("paypal: amount %.2f does not match the sum of item, tax, shipping, handling, insurance and discount amounts %.2f", p.Amount, total)
+	}
+	return nil
+}
+
+func (p *PaymentRequest) addValues(values url.Values, prefix string) {
+	if len(p.PaymentRequestId) != 0 {
+		values.Add(prefix+"PAYMENTREQUESTID", p.PaymentRequestId)
+	}
+	if len(p.SellerAccountId) != 0 {
+		values.Add(prefix+"SELLERPAYPALACCOUNTID", p.SellerAccountId)
+	}
+	values.Add(prefix+"AMT", fmt.Sprintf("%.2f", p.Amount))
+	values.Add(prefix+"CURRENCYCODE", p.CurrencyCode)
+	if len(p.PaymentAction) != 0 {
+		values.Add(prefix+"PAYMENTACTION", p.PaymentAction)
+	}
+	if len(p.InvoiceNumber) != 0 {
+		values.Add(prefix+"INVNUM", p.InvoiceNumber)
+	}
+	if len(p.Description) != 0 {
+		values.Add(prefix+"DESC", p.Description)
+	}
+	if len(p.NotifyUrl) != 0 {
+		values.Add(prefix+"NOTIFYURL", p.NotifyUrl)
+	}
+	if len(p.Custom) != 0 {
+		values.Add(prefix+"CUSTOM", p.Custom)
+	}
+	if len(p.SoftDescriptor) != 0 {
+		values.Add(prefix+"SOFTDESCRIPTOR", p.SoftDescriptor)
+	}
+
+	items := p.Items
+	if len(p.ItemCategory) != 0 {
+		items = make([]LineItem, len(p.Items))
+		for i, item := range p.Items {
+			if len(item.Category) == 0 {
+				item.Category = p.ItemCategory
+			}
+			items[i] = item
+		}
+	}
+	addLineItems(values, prefix, items)
+	if p.itemized() {
+		values.Add(prefix+"ITEMAMT", fmt.Sprintf("%.2f", p.itemAmount()))
+		amounts := map[string]float64{
+			"TAXAMT":       p.taxAmount(),
+			"SHIPPINGAMT":  p.ShippingAmount,
+			"HANDLINGAMT":  p.HandlingAmount,
+			"INSURANCEAMT": p.InsuranceAmount,
+			"SHIPDISCAMT":  p.ShippingDiscount,
+		}
+		for key, amount := range amounts {
+			if amount != 0 {
+				values.Add(prefix+key, fmt.Sprintf("%.2f", amount))
+			}
+		}
+	}
+	if p.ShipToAddress != nil {
+		addAddress(values, prefix, p.ShipToAddress)
+	}
+}
+
+// PaymentRequestDetails is a payment request as returned by
+// GetExpressCheckoutDetails, with amounts in its CurrencyCode.
+type PaymentRequestDetails struct {
+	PaymentRequestId string
+	SellerAccountId  string
+	Amount           Money
+	CurrencyCode     string
+	PaymentAction    string
+	InvoiceNumber    string
+	Description      string
+	Items            []ItemDetails
+	ItemAmount       Money
+	TaxAmount        Money
+	ShippingAmount   Money
+	HandlingAmount   Money
+	InsuranceAmount  Money
+	ShippingDiscount Money
+	ShipToAddress    Address
+	Custom           string
+}
+
+func parsePaymentRequestDetails(values url.Values, prefix string) PaymentRequestDetails {
+	currencyCode := values.Get(prefix + "CURRENCYCODE")
+	return PaymentRequestDetails{
+		PaymentRequestId: values.Get(prefix + "PAYMENTREQUESTID"),
+		SellerAccountId:  values.Get(prefix + "SELLERPAYPALACCOUNTID"),
+		Amount:           parseMoney(values, prefix+"AMT", currencyCode),
+		CurrencyCode:     currencyCode,
+		PaymentAction:    values.Get(prefix + "PAYMENTACTION"),
+		InvoiceNumber:    values.Get(prefix + "INVNUM"),
+		Description:      values.Get(prefix + "DESC"),
+		Items:            parseLineItems(values, prefix, currencyCode),
+		ItemAmount:       parseMoney(values, prefix+"ITEMAMT", currencyCode),
+		TaxAmount:        parseMoney(values, prefix+"TAXAMT", currencyCode),
+		ShippingAmount:   parseMoney(values, prefix+"SHIPPINGAMT", currencyCode),
+		HandlingAmount:   parseMoney(values, prefix+"HANDLINGAMT", currencyCode),
+		InsuranceAmount:  parseMoney(values, prefix+"INSURANCEAMT", currencyCode),
+		ShippingDiscount: parseMoney(values, prefix+"SHIPDISCAMT", currencyCode),
+		ShipToAddress:    parseAddress(values, prefix),
+		Custom:           values.Get(prefix + "CUSTOM"),
+	}
+}
+
+// MAX_PAYMENT_REQUESTS is the number of parallel payments PayPal accepts in a
+// single checkout.
+const MAX_PAYMENT_REQUESTS = 10
+
+func paymentRequestPrefix(n int) string {
+	return fmt.Sprintf("PAYMENTREQUEST_%d_", n)
+}
+
+func addPaymentRequests(values url.Values, requests []PaymentRequest) error {
+	if len(requests) == 0 {
+		return errors.New("paypal: at least one payment request is required")
+	}
+	if len(requests) > MAX_PAYMENT_REQUESTS {
+		return fmt.Errorf("paypal: at most %d payment requests are allowed, got %d", MAX_PAYMENT_REQUESTS, len(requests))
+	}
+	for n := range requests {
+		if err := requests[n].Validate(); err != nil {
+			if len(requests) == 1 {
+				return err
+			}
+			return fmt.Errorf("%v (payment request %d)", err, n)
+		}
+	}
+
+	for n := range requests {
+		requests[n].addValues(values, paymentRequestPrefix(n))
+	}
+	return nil
+}
+
+// CheckoutBranding styles the PayPal hosted pages. Colors are six digit hex
+// codes without the leading '#'; image URLs should be served over HTTPS to
+// avoid mixed content warnings for the buyer.
+type CheckoutBranding struct {
+	BrandName             string
+	LogoImage             string
+	CartBorderColor       string
+	PageStyle             string
+	HeaderImage           string
+	HeaderBorderColor     string
+	HeaderBackColor       string
+	PayflowColor          string
+	CustomerServiceNumber string
+}
+
+func (b *CheckoutBranding) addValues(values url.Values) error {
+	colors := map[string]string{
+		"CARTBORDERCOLOR": b.CartBorderColor,
+		"HDRBORDERCOLOR":  b.HeaderBorderColor,
+		"HDRBACKCOLOR":    b.HeaderBackColor,
+		"PAYFLOWCOLOR":    b.PayflowColor,
+	}
+	for key, color := range colors {
+		if len(color) == 0 {
+			continue
+		}
+		if _, err := strconv.ParseUint(color, 16, 32); err != nil || len(color) != 6 {
+			return errors.New("paypal: invalid " + key + " color " + color)
+		}
+		values.Add(key, color)
+	}
+
+	fields := map[string]string{
+		"BRANDNAME":             b.BrandName,
+		"LOGOIMG":               b.LogoImage,
+		"PAGESTYLE":             b.PageStyle,
+		"HDRIMG":                b.HeaderImage,
+		"CUSTOMERSERVICENUMBER": b.CustomerServiceNumber,
+	}
+	for key, value := range fields {
+		if len(value) != 0 {
+			values.Add(key, value)
+		}
+	}
+	return nil
+}
+
+// SetExpressCheckoutRequest holds the SetExpressCheckout fields. Zero values
+// are left out of the request so PayPal applies its own defaults.
+// NoShipping set to ShippingRequired makes PayPal take the shipping address
+// from the buyer's account when none is supplied. AddressOverride shows the
+// payment request's ShipToAddress on the PayPal review page instead of the
+// address on file with PayPal. Marketplaces paying several sellers at once add
+// one PaymentRequest per seller, each with its own SellerAccountId and
+// PaymentRequestId.
+type SetExpressCheckoutRequest struct {
+	Token              string
+	ReturnUrl          string
+	CancelUrl          string
+	PaymentRequests    []PaymentRequest
+	BillingAgreements  []BillingAgreement
+	NoShipping         ShippingDisplay
+	AddressOverride    bool
+	ShippingOptions    []ShippingOption
+	ReqConfirmShipping bool
+	ReqBillingAddress  bool
+	SolutionType       SolutionType
+	LandingPage        LandingPage
+	TotalType          string
+	ChannelType        string
+	BuyerEmailOptIn    bool
+	Branding           CheckoutBranding
+	LocaleCode         string
+	Email              string
+	AllowNote          bool
+	SurveyQuestion     string
+	SurveyChoices      []string
+	MaxAmount          float64
+
+	// GiropaySuccessUrl, GiropayCancelUrl and BankTxnPendingUrl are where the
+	// buyer lands after completing, cancelling or starting a giropay or bank
+	// transfer payment.
+	GiropaySuccessUrl string
+	GiropayCancelUrl  string
+	BankTxnPendingUrl string
+
+	// CallbackUrl registers an Instant Update endpoint, such as one served by
+	// CallbackHandler, that PayPal asks for shipping options once it knows the
+	// buyer's address. ShippingOptions are then the flat-rate fallback used if
+	// the callback fails or takes longer than CallbackTimeout, and MaxAmount
+	// must allow for the most expensive option.
+	CallbackUrl     string
+	CallbackTimeout time.Duration
+	CallbackVersion string
+}
+
+func (r *SetExpressCheckoutRequest) values() (url.Values, error) {
+	if len(r.ReturnUrl) == 0 || len(r.CancelUrl) == 0 {
+		return nil, errors.New("paypal: express checkout requires a return and cancel URL")
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "SetExpressCheckout")
+	if len(r.Token) != 0 {
+		values.Add("TOKEN", r.Token)
+	}
+	values.Add("RETURNURL", r.ReturnUrl)
+	values.Add("CANCELURL", r.CancelUrl)
+	if err := addPaymentRequests(values, r.PaymentRequests); err != nil {
+		return nil, err
+	}
+	addBillingAgreements(values, r.BillingAgreements)
+	if err := r.Branding.addValues(values); err != nil {
+		return nil, err
+	}
+	if len(r.LocaleCode) != 0 {
+		if !IsSupportedLocale(r.LocaleCode) {
+			return nil, errors.New("paypal: unsupported locale code " + r.LocaleCode)
+		}
+		values.Add("LOCALECODE", r.LocaleCode)
+	}
+
+	if r.NoShipping != 0 {
+		values.Add("NOSHIPPING", fmt.Sprintf("%d", r.NoShipping))
+	}
+	if r.AddressOverride {
+		if r.PaymentRequests[0].ShipToAddress == nil {
+			return nil, errors.New("paypal: address override requires a shipping address")
+		}
+		if err := r.PaymentRequests[0].ShipToAddress.Validate(); err != nil {
+			return nil, err
+		}
+		values.Add("ADDROVERRIDE", "1")
+	}
+	if len(r.ShippingOptions) != 0 {
+		if err := validateShippingOptions(r.ShippingOptions); err != nil {
+			return nil, err
+		}
+		addShippingOptions(values, r.ShippingOptions)
+	}
+	if len(r.CallbackUrl) != 0 {
+		if len(r.ShippingOptions) == 0 {
+			return nil, errors.New("paypal: a callback requires flat-rate shipping options as a fallback")
+		}
+		if r.MaxAmount == 0 {
+			return nil, errors.New("paypal: a callback requires a maximum amount")
+		}
+		values.Add("CALLBACK", r.CallbackUrl)
+		if r.CallbackTimeout != 0 {
+			if r.CallbackTimeout < time.Second || r.CallbackTimeout > 6*time.Second {
+				return nil, errors.New("paypal: callback timeout must be between 1 and 6 seconds")
+			}
+			values.Add("CALLBACKTIMEOUT", fmt.Sprintf("%d", int(r.CallbackTimeout/time.Second)))
+		}
+		callbackVersion := r.CallbackVersion
+		if len(callbackVersion) == 0 {
+			callbackVersion = CALLBACK_VERSION
+		}
+		values.Add("CALLBACKVERSION", callbackVersion)
+	}
+	if r.MaxAmount != 0 {
+		var total float64
+		for _, request := range r.PaymentRequests {
+			total += request.Amount
+		}
+		if cents(r.MaxAmount) < cents(total) {
+			return nil, fmt.Errorf("paypal: maximum amount %.2f is below the order total %.2f", r.MaxAmount, total)
+		}
+		values.Add("MAXAMT", fmt.Sprintf("%.2f", r.MaxAmount))
+	}
+	if r.AllowNote {
+		values.Add("ALLOWNOTE", "1")
+	}
+	if len(r.SurveyQuestion) != 0 {
+		if len(r.SurveyChoices) == 0 {
+			return nil, errors.New("paypal: a survey question requires answer choices")
+		}
+		values.Add("SURVEYENABLE", "1")
+		values.Add("SURVEYQUESTION", r.SurveyQuestion)
+		for i, choice := range r.SurveyChoices {
+			values.Add(fmt.Sprintf("%s%d", "L_SURVEYCHOICE", i), choice)
+		}
+	}
+	if r.ReqConfirmShipping {
+		values.Add("REQCONFIRMSHIPPING", "1")
+	}
+	if r.ReqBillingAddress {
+		values.Add("REQBILLINGADDRESS", "1")
+	}
+	if r.BuyerEmailOptIn {
+		values.Add("BUYEREMAILOPTINENABLE", "1")
+	}
+	if len(r.SolutionType) != 0 {
+		if r.SolutionType != SolutionSole && r.SolutionType != SolutionMark {
+			return nil, errors.New("paypal: invalid solution type " + string(r.SolutionType))
+		}
+		values.Add("SOLUTIONTYPE", string(r.SolutionType))
+	}
+	if len(r.LandingPage) != 0 {
+		if r.LandingPage != LandingBilling && r.LandingPage != LandingLogin {
+			return nil, errors.New("paypal: invalid landing page " + string(r.LandingPage))
+		}
+		values.Add("LANDINGPAGE", string(r.LandingPage))
+	}
+	optional := map[string]string{
+		"TOTALTYPE":         r.TotalType,
+		"CHANNELTYPE":       r.ChannelType,
+		"EMAIL":             r.Email,
+		"GIROPAYSUCCESSURL": r.GiropaySuccessUrl,
+		"GIROPAYCANCELURL":  r.GiropayCancelUrl,
+		"BANKTXNPENDINGURL": r.BankTxnPendingUrl,
+	}
+	for key, value := range optional {
+		if len(value) != 0 {
+			values.Add(key, value)
+		}
+	}
+
+	return values, nil
+}
+
+// MaxFinalAmount returns the largest amount DoExpressCheckoutPayment may
+// charge for a checkout set up with the given estimate: 115% of it, rounded
+// down to the cent.
+func MaxFinalAmount(estimate float64) float64 {
+	return float64(cents(estimate)*115/100) / 100
+}
+
+// ValidateFinalAmount applies PayPal's 115% rule locally, so an order whose
+// shipping or tax pushed it too far above the estimate shown to the buyer is
+// caught before DoExpressCheckoutPayment fails.
+func ValidateFinalAmount(estimate, final float64) error {
+	if max := MaxFinalAmount(estimate); cents(final) > cents(max) {
+		return fmt.Errorf("paypal: final amount %.2f exceeds 115%% of the estimate %.2f (at most %.2f)", final, estimate, max)
+	}
+	return nil
+}
+
+func (pClient *PayPalClient) SetExpressCheckout(request SetExpressCheckoutRequest) (*PayPalResponse, error) {
+	return pClient.SetExpressCheckoutCtx(context.Background(), request)
+}
+
+func (pClient *PayPalClient) SetExpressCheckoutCtx(ctx context.Context, request SetExpressCheckoutRequest) (*PayPalResponse, error) {
+	values, err := request.values()
+	if err != nil {
+		return nil, err
+	}
+	return pClient.PerformRequestCtx(ctx, values)
+}
+
+// SetExpressCheckoutPhysicalGoods is the physical goods counterpart of
+// SetExpressCheckoutDigitalGoods: the buyer must provide a shipping address and
+// the payment amount is the item total plus shippingAmount.
+func (pClient *PayPalClient) SetExpressCheckoutPhysicalGoods(currencyCode, returnURL, cancelURL, invnum string, items []LineItem, shippingAmount float64) (*PayPalResponse, error) {
+	return pClient.SetExpressCheckoutPhysicalGoodsCtx(context.Background(), currencyCode, returnURL, cancelURL, invnum, items, shippingAmount)
+}
+
+func (pClient *PayPalClient) SetExpressCheckoutPhysicalGoodsCtx(ctx context.Context, currencyCode, returnURL, cancelURL, invnum string, items []LineItem, shippingAmount float64) (*PayPalResponse, error) {
+	itemAmount := (&PaymentRequest{Items: items}).itemAmount()
+	return pClient.SetExpressCheckoutCtx(ctx, SetExpressCheckoutRequest{
+		ReturnUrl: returnURL,
+		CancelUrl: cancelURL,
+		PaymentRequests: []PaymentRequest{{
+			Amount:         itemAmount + shippingAmount,
+			CurrencyCode:   currencyCode,
+			PaymentAction:  "Sale",
+			InvoiceNumber:  invnum,
+			Items:          items,
+			ItemCategory:   "Physical",
+			ShippingAmount: shippingAmount,
+		}},
+		NoShipping: ShippingRequired,
+	})
+}
+
+// ExpressCheckoutDetails is the result of GetExpressCheckoutDetails.
+// ShippingOption is the option the buyer picked, or nil when the checkout
+// offered no shipping options. ShipToAddress and ShippingAmount repeat those
+// of the first payment request. Note and SurveyChoice are the buyer's answers
+// when the checkout set AllowNote or a SurveyQuestion. The insurance and gift
+// fields carry the buyer's choices when the checkout offered them.
+type ExpressCheckoutDetails struct {
+	*PayPalResponse
+	Token             string
+	CheckoutStatus    CheckoutStatus
+	Payer             Payer
+	Note              string
+	SurveyChoice      string
+	ShipToAddress     Address
+	ShippingAmount    Money
+	ShippingOption    *SelectedShippingOption
+	PaymentRequests   []PaymentRequestDetails
+	InsuranceSelected bool
+	InsuranceAmount   Money
+	GiftMessage       string
+	GiftReceipt       bool
+	GiftWrapName      string
+	GiftWrapAmount    Money
+}
+
+// SelectedShippingOption is the shipping option the buyer picked.
+type SelectedShippingOption struct {
+	Name      string
+	Amount    Money
+	IsDefault bool
+}
+
+func newExpressCheckoutDetails(r *PayPalResponse) *ExpressCheckoutDetails {
+	currencyCode := r.Values.Get("PAYMENTREQUEST_0_CURRENCYCODE")
+	details := &ExpressCheckoutDetails{
+		PayPalResponse:    r,
+		Token:             r.Values.Get("TOKEN"),
+		CheckoutStatus:    CheckoutStatus(r.Values.Get("CHECKOUTSTATUS")),
+		Payer:             parsePayer(r.Values),
+		Note:              r.Values.Get("PAYMENTREQUEST_0_NOTETEXT"),
+		SurveyChoice:      r.Values.Get("SURVEYCHOICESELECTED"),
+		ShipToAddress:     parseAddress(r.Values, "PAYMENTREQUEST_0_"),
+		ShippingAmount:    parseMoney(r.Values, "PAYMENTREQUEST_0_SHIPPINGAMT", currencyCode),
+		InsuranceSelected: r.Values.Get("INSURANCEOPTIONSELECTED") == "true",
+		InsuranceAmount:   parseMoney(r.Values, "PAYMENTREQUEST_0_INSURANCEAMT", currencyCode),
+		GiftMessage:       r.Values.Get("GIFTMESSAGE"),
+		GiftReceipt:       r.Values.Get("GIFTRECEIPTENABLE") == "true",
+		GiftWrapName:      r.Values.Get("GIFTWRAPNAME"),
+		GiftWrapAmount:    parseMoney(r.Values, "GIFTWRAPAMOUNT", currencyCode),
+	}
+	for n := 0; n < MAX_PAYMENT_REQUESTS; n++ {
+		prefix := paymentRequestPrefix(n)
+		if _, ok := r.Values[prefix+"AMT"]; !ok {
+			break
+		}
+		details.PaymentRequests = append(details.PaymentRequests, parsePaymentRequestDetails(r.Values, prefix))
+	}
+	if len(details.Note) == 0 {
+		details.Note = r.Values.Get("NOTE")
+	}
+	if name := r.Values.Get("SHIPPINGOPTIONNAME"); len(name) != 0 {
+		details.ShippingOption = &SelectedShippingOption{
+			Name:      name,
+			Amount:    parseMoney(r.Values, "SHIPPINGOPTIONAMOUNT", currencyCode),
+			IsDefault: r.Values.Get("SHIPPINGOPTIONISDEFAULT") == "true",
+		}
+	}
+	return details
+}
+
+type DoExpressCheckoutRequest struct {
+	Token           string
+	PayerId         string
+	PaymentRequests []PaymentRequest
+}
+
+// DoExpressCheckoutResponse holds one PaymentInfo per payment request, in the
+// order of the request. RedirectRequired means the buyer chose giropay or a
+// bank transfer and must be sent to CompleteCheckoutUrl to finish paying.
+type DoExpressCheckoutResponse struct {
+	*PayPalResponse
+	Token            string
+	RedirectRequired bool
+	Payments         []PaymentInfo
+}
+
+func newDoExpressCheckoutResponse(r *PayPalResponse) *DoExpressCheckoutResponse {
+	response := &DoExpressCheckoutResponse{
+		PayPalResponse:   r,
+		Token:            r.Values.Get("TOKEN"),
+		RedirectRequired: strings.ToLower(r.Values.Get("REDIRECTREQUIRED")) == "true",
+	}
+	for n := 0; n < MAX_PAYMENT_REQUESTS; n++ {
+		prefix := fmt.Sprintf("PAYMENTINFO_%d_", n)
+		_, hasTransaction := r.Values[prefix+"TRANSACTIONID"]
+		_, hasError := r.Values[prefix+"ERRORCODE"]
+		if !hasTransaction && !hasError {
+			break
+		}
+		response.Payments = append(response.Payments, parsePaymentInfo(r.Values, prefix, r.settleCurrency))
+	}
+	return response
+}
+
+func (pClient *PayPalClient) DoExpressCheckout(request DoExpressCheckoutRequest) (*DoExpressCheckoutResponse, error) {
+	return pClient.DoExpressCheckoutCtx(context.Background(), request)
+}
+
+func (pClient *PayPalClient) DoExpressCheckoutCtx(ctx context.Context, request DoExpressCheckoutRequest) (*DoExpressCheckoutResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "DoExpressCheckoutPayment")
+	values.Add("TOKEN", request.Token)
+	values.Add("PAYERID", request.PayerId)
+	if err := addPaymentRequests(values, request.PaymentRequests); err != nil {
+		return nil, err
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	checkout := newDoExpressCheckoutResponse(response)
+	if partial := newPartialPaymentError(checkout.Payments, err); partial != nil {
+		err = partial
+	}
+	return checkout, err
+}
+
+// Payment returns the payment made for the payment request with the given
+// PaymentRequestId, or nil if there is none.
+func (r *DoExpressCheckoutResponse) Payment(paymentRequestId string) *PaymentInfo {
+	for i := range r.Payments {
+		if r.Payments[i].PaymentRequestId == paymentRequestId {
+			return &r.Payments[i]
+		}
+	}
+	return nil
+}
+
+func (r *DoExpressCheckoutResponse) CompleteCheckoutUrl() string {
+	query := url.Values{}
+	query.Set("cmd", "_complete-express-checkout")
+	query.Add("token", r.Token)
+	return fmt.Sprintf("%s?%s", r.checkoutBaseUrl(), query.Encode())
+}
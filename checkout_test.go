@@ -0,0 +1,107 @@
+package paypal
+
+import (
+	"net/url"
+	"testing"
+)
+
+func newTestCheckoutRequest() SetExpressCheckoutRequest {
+	return SetExpressCheckoutRequest{
+		ReturnUrl:       "https://shop.example.com/return",
+		CancelUrl:       "https://shop.example.com/cancel",
+		PaymentRequests: []PaymentRequest{{Amount: 10, CurrencyCode: "USD", PaymentAction: "Sale"}},
+	}
+}
+
+func checkValues(t *testing.T, values url.Values, want map[string]string) {
+	t.Helper()
+	for key, value := range want {
+		if got := values.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestSetExpressCheckoutBuyerFields(t *testing.T) {
+	request := newTestCheckoutRequest()
+	request.Email = "buyer@example.com"
+	request.AllowNote = true
+	request.SurveyQuestion = "How did you hear about us?"
+	request.SurveyChoices = []string{"Search", "Friend"}
+
+	values, err := request.values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, values, map[string]string{
+		"EMAIL":           "buyer@example.com",
+		"ALLOWNOTE":       "1",
+		"SURVEYENABLE":    "1",
+		"SURVEYQUESTION":  "How did you hear about us?",
+		"L_SURVEYCHOICE0": "Search",
+		"L_SURVEYCHOICE1": "Friend",
+	})
+
+	request = newTestCheckoutRequest()
+	values, err = request.values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"EMAIL", "ALLOWNOTE", "SURVEYENABLE"} {
+		if _, ok := values[key]; ok {
+			t.Errorf("%s sent although not set", key)
+		}
+	}
+}
+
+func TestSetExpressCheckoutGiropayUrls(t *testing.T) {
+	request := newTestCheckoutRequest()
+	request.GiropaySuccessUrl = "https://shop.example.com/giropay/success"
+	request.GiropayCancelUrl = "https://shop.example.com/giropay/cancel"
+	request.BankTxnPendingUrl = "https://shop.example.com/bank/pending"
+
+	values, err := request.values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, values, map[string]string{
+		"GIROPAYSUCCESSURL": "https://shop.example.com/giropay/success",
+		"GIROPAYCANCELURL":  "https://shop.example.com/giropay/cancel",
+		"BANKTXNPENDINGURL": "https://shop.example.com/bank/pending",
+	})
+}
+
+func TestCompleteCheckoutUrl(t *testing.T) {
+	body := url.Values{
+		"ACK":                         {"Success"},
+		"TOKEN":                       {"EC-0TX12345AB678901C"},
+		"REDIRECTREQUIRED":            {"true"},
+		"PAYMENTINFO_0_TRANSACTIONID": {"4TT123456X7890123"},
+		"PAYMENTINFO_0_PAYMENTSTATUS": {"Pending"},
+		"PAYMENTINFO_0_ERRORCODE":     {"0"},
+		"PAYMENTINFO_0_ACK":           {"Success"},
+	}.Encode()
+	for _, test := range []struct {
+		sandbox bool
+		want    string
+	}{
+		{false, CHECKOUT_PRODUCTION_URL + "?cmd=_complete-express-checkout&token=EC-0TX12345AB678901C"},
+		{true, CHECKOUT_SANDBOX_URL + "?cmd=_complete-express-checkout&token=EC-0TX12345AB678901C"},
+	} {
+		pClient, _ := newTestClient(t, body, WithSandbox(test.sandbox))
+		response, err := pClient.DoExpressCheckout(DoExpressCheckoutRequest{
+			Token:           "EC-0TX12345AB678901C",
+			PayerId:         "PAYER",
+			PaymentRequests: []PaymentRequest{{Amount: 10, CurrencyCode: "EUR", PaymentAction: "Sale"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !response.RedirectRequired {
+			t.Error("RedirectRequired not parsed")
+		}
+		if got := response.CompleteCheckoutUrl(); got != test.want {
+			t.Errorf("sandbox %t: got %s, want %s", test.sandbox, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package paypal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Config holds the client settings read by LoadConfig and ReadConfig.
+// Environment is "sandbox" or "live", live when empty. CertFile and KeyFile
+// select certificate credentials instead of Signature.
+type Config struct {
+	Username     string `json:"username" yaml:"username"`
+	Password     string `json:"password" yaml:"password"`
+	Signature    string `json:"signature,omitempty" yaml:"signature,omitempty"`
+	CertFile     string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile      string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	Environment  string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Version      string `json:"version,omitempty" yaml:"version,omitempty"`
+	ButtonSource string `json:"buttonSource,omitempty" yaml:"buttonSource,omitempty"`
+}
+
+// LoadConfig reads a JSON config file. Use ReadConfig for other formats.
+func LoadConfig(path string) (Config, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".json" {
+		return Config{}, errors.New("paypal: LoadConfig only reads JSON, use ReadConfig for " + path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer file.Close()
+	config, err := ReadConfig(file, nil)
+	if err != nil {
+		return config, fmt.Errorf("%v in %s", err, path)
+	}
+	return config, nil
+}
+
+// ReadConfig decodes a config with unmarshal, json.Unmarshal if nil. The
+// yaml tags of Config let YAML files be read without this package depending
+// on a YAML library:
+//
+//	config, err := paypal.ReadConfig(file, yaml.Unmarshal)
+func ReadConfig(r io.Reader, unmarshal func(data []byte, v interface{}) error) (Config, error) {
+	var config Config
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return config, err
+	}
+	if err := unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("paypal: cannot read config: %v", err)
+	}
+	return config, nil
+}
+
+// NewClientFromEnv configures the client from PAYPAL_USER, PAYPAL_PWD,
+// PAYPAL_SIGNATURE and PAYPAL_ENV ("sandbox" or "live"). Options are applied
+// after the environment.
+func NewClientFromEnv(opts ...Option) (*PayPalClient, error) {
+	return NewClientFromConfig(Config{
+		Username:    os.Getenv("PAYPAL_USER"),
+		Password:    os.Getenv("PAYPAL_PWD"),
+		Signature:   os.Getenv("PAYPAL_SIGNATURE"),
+		Environment: os.Getenv("PAYPAL_ENV"),
+	}, opts...)
+}
+
+// NewClientFromConfig returns a client for config. Options are applied after
+// the config.
+func NewClientFromConfig(config Config, opts ...Option) (*PayPalClient, error) {
+	if len(config.Username) == 0 || len(config.Password) == 0 {
+		return nil, errors.New("paypal: config lacks the API username or password")
+	}
+
+	var usesSandbox bool
+	switch strings.ToLower(config.Environment) {
+	case "", "live", "production":
+	case "sandbox":
+		usesSandbox = true
+	default:
+		return nil, errors.New("paypal: unknown environment " + config.Environment)
+	}
+
+	var credentials Credentials
+	switch {
+	case len(config.CertFile) != 0:
+		keyFile := config.KeyFile
+		if len(keyFile) == 0 {
+			keyFile = config.CertFile
+		}
+		certificate, err := LoadCertificateCredentials(config.Username, config.Password, config.CertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("paypal: cannot load API certificate: %v", err)
+		}
+		credentials = certificate
+	case len(config.Signature) != 0:
+		credentials = SignatureCredentials{config.Username, config.Password, config.Signature}
+	default:
+		return nil, errors.New("paypal: config lacks an API signature or certificate")
+	}
+
+	configured := []Option{WithSandbox(usesSandbox)}
+	if len(config.Version) != 0 {
+		configured = append(configured, WithVersion(config.Version))
+	}
+	if len(config.ButtonSource) != 0 {
+		configured = append(configured, WithButtonSource(config.ButtonSource))
+	}
+	return NewClient(credentials, append(configured, opts...)...), nil
+}
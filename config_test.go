@@ -0,0 +1,75 @@
+package paypal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paypal.json")
+	data := `{"username": "api_user", "password": "secret", "signature": "sig", "environment": "sandbox", "buttonSource": "Shop_Cart_EC"}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{Username: "api_user", Password: "secret", Signature: "sig", Environment: "sandbox", ButtonSource: "Shop_Cart_EC"}
+	if config != want {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+	pClient, err := NewClientFromConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pClient.usesSandbox || pClient.ButtonSource() != "Shop_Cart_EC" {
+		t.Errorf("client not configured from %+v", config)
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "paypal.yaml")); err == nil {
+		t.Error("LoadConfig accepted a YAML file")
+	}
+}
+
+func TestReadConfigUnmarshal(t *testing.T) {
+	var decoded string
+	unmarshal := func(data []byte, v interface{}) error {
+		decoded = string(data)
+		v.(*Config).Username = "from-func"
+		return nil
+	}
+	config, err := ReadConfig(strings.NewReader("username: from-func"), unmarshal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded != "username: from-func" || config.Username != "from-func" {
+		t.Errorf("got %+v from %q", config, decoded)
+	}
+
+	failing := func([]byte, interface{}) error { return errors.New("bad input") }
+	if _, err := ReadConfig(strings.NewReader("x"), failing); err == nil {
+		t.Error("unmarshal error not returned")
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("PAYPAL_USER", "api_user")
+	t.Setenv("PAYPAL_PWD", "secret")
+	t.Setenv("PAYPAL_SIGNATURE", "sig")
+	t.Setenv("PAYPAL_ENV", "staging")
+	if _, err := NewClientFromEnv(); err == nil {
+		t.Error("unknown environment accepted")
+	}
+	t.Setenv("PAYPAL_ENV", "sandbox")
+	pClient, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pClient.usesSandbox || pClient.username != "api_user" || pClient.signature != "sig" {
+		t.Errorf("client not configured from the environment")
+	}
+}
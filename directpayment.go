@@ -0,0 +1,142 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// AVSResult is the address verification result of a card payment. The digit
+// codes are returned for Maestro cards.
+type AVSResult string
+
+const (
+	AVSAddressOnly              AVSResult = "A"
+	AVSInternationalAddressOnly AVSResult = "B"
+	AVSInternationalNoMatch     AVSResult = "C"
+	AVSInternationalMatch       AVSResult = "D"
+	AVSNotAllowed               AVSResult = "E"
+	AVSUKMatch                  AVSResult = "F"
+	AVSGlobalUnavailable        AVSResult = "G"
+	AVSInternationalUnavailable AVSResult = "I"
+	AVSAddressAndPostalMatch    AVSResult = "M"
+	AVSNoMatch                  AVSResult = "N"
+	AVSInternationalPostalOnly  AVSResult = "P"
+	AVSRetry                    AVSResult = "R"
+	AVSNotSupported             AVSResult = "S"
+	AVSUnavailable              AVSResult = "U"
+	AVSNineDigitZipOnly         AVSResult = "W"
+	AVSNineDigitZipMatch        AVSResult = "X"
+	AVSMatch                    AVSResult = "Y"
+	AVSZipOnly                  AVSResult = "Z"
+	AVSMaestroMatch             AVSResult = "0"
+	AVSMaestroNoMatch           AVSResult = "1"
+	AVSMaestroPartialMatch      AVSResult = "2"
+	AVSMaestroNotApplicable     AVSResult = "3"
+	AVSMaestroUnavailable       AVSResult = "4"
+)
+
+func (r AVSResult) String() string {
+	return string(r)
+}
+
+// IsMatch reports whether both the street address and the postal code matched.
+func (r AVSResult) IsMatch() bool {
+	switch r {
+	case AVSInternationalMatch, AVSUKMatch, AVSAddressAndPostalMatch, AVSNineDigitZipMatch, AVSMatch, AVSMaestroMatch:
+		return true
+	}
+	return false
+}
+
+// IsPartialMatch reports whether only the street address or only the postal
+// code matched.
+func (r AVSResult) IsPartialMatch() bool {
+	switch r {
+	case AVSAddressOnly, AVSInternationalAddressOnly, AVSInternationalPostalOnly, AVSNineDigitZipOnly, AVSZipOnly, AVSMaestroPartialMatch:
+		return true
+	}
+	return false
+}
+
+// CVVResult is the card security code verification result of a card payment.
+// The digit codes are returned for Maestro cards.
+type CVVResult string
+
+const (
+	CVVMatch                 CVVResult = "M"
+	CVVNoMatch               CVVResult = "N"
+	CVVNotProcessed          CVVResult = "P"
+	CVVNotSupported          CVVResult = "S"
+	CVVUnavailable           CVVResult = "U"
+	CVVNoResponse            CVVResult = "X"
+	CVVMaestroMatch          CVVResult = "0"
+	CVVMaestroNoMatch        CVVResult = "1"
+	CVVMaestroNotImplemented CVVResult = "2"
+	CVVMaestroNotPresent     CVVResult = "3"
+	CVVMaestroUnavailable    CVVResult = "4"
+)
+
+func (r CVVResult) String() string {
+	return string(r)
+}
+
+func (r CVVResult) IsMatch() bool {
+	return r == CVVMatch || r == CVVMaestroMatch
+}
+
+// IsMismatch reports whether the code was checked and did not match; results
+// where no check took place report false.
+func (r CVVResult) IsMismatch() bool {
+	return r == CVVNoMatch || r == CVVMaestroNoMatch
+}
+
+type DirectPaymentResponse struct {
+	*PayPalResponse
+	TransactionId string
+	AvsCode       AVSResult
+	Cvv2Match     CVVResult
+	Amount        Money
+	FraudFilters  FraudFilters
+}
+
+// DoDirectPayment charges a card directly (Website Payments Pro). The buyer's
+// IP address is mandatory for PayPal's fraud checks. The returned AvsCode and
+// Cvv2Match carry the address and card security code verification results.
+func (pClient *PayPalClient) DoDirectPayment(card CreditCard, paymentAction string, amount float64, currencyCode, ipAddress string) (*DirectPaymentResponse, error) {
+	return pClient.DoDirectPaymentCtx(context.Background(), card, paymentAction, amount, currencyCode, ipAddress)
+}
+
+func (pClient *PayPalClient) DoDirectPaymentCtx(ctx context.Context, card CreditCard, paymentAction string, amount float64, currencyCode, ipAddress string) (*DirectPaymentResponse, error) {
+	if err := card.Validate(); err != nil {
+		return nil, err
+	}
+	if paymentAction != "Sale" && paymentAction != "Authorization" {
+		return nil, errors.New("paypal: invalid direct payment action " + paymentAction)
+	}
+	if len(ipAddress) == 0 {
+		return nil, errors.New("paypal: direct payments require the buyer's IP address")
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "DoDirectPayment")
+	values.Add("PAYMENTACTION", paymentAction)
+	values.Add("IPADDRESS", ipAddress)
+	values.Add("AMT", fmt.Sprintf("%.2f", amount))
+	values.Add("CURRENCYCODE", currencyCode)
+	card.addValues(values)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &DirectPaymentResponse{
+		PayPalResponse: response,
+		TransactionId:  response.Values.Get("TRANSACTIONID"),
+		AvsCode:        AVSResult(response.Values.Get("AVSCODE")),
+		Cvv2Match:      CVVResult(response.Values.Get("CVV2MATCH")),
+		Amount:         parseMoney(response.Values, "AMT", response.Values.Get("CURRENCYCODE")),
+		FraudFilters:   parseFraudFilters(response.Values, ""),
+	}, err
+}
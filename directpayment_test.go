@@ -0,0 +1,66 @@
+package paypal
+
+import "testing"
+
+func TestDoDirectPaymentVerificationResults(t *testing.T) {
+	pClient, _ := newTestClient(t, "ACK=Success&TRANSACTIONID=1AB23456CD789012E&AMT=10.00&CURRENCYCODE=USD&AVSCODE=Z&CVV2MATCH=M")
+	response, err := pClient.DoDirectPayment(newTestCard(), "Sale", 10, "USD", "203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.AvsCode != AVSZipOnly || !response.AvsCode.IsPartialMatch() || response.AvsCode.IsMatch() {
+		t.Errorf("AvsCode = %q, want a partial match", response.AvsCode)
+	}
+	if !response.Cvv2Match.IsMatch() {
+		t.Errorf("Cvv2Match = %q, want a match", response.Cvv2Match)
+	}
+}
+
+func TestAVSResult(t *testing.T) {
+	tests := []struct {
+		result  AVSResult
+		match   bool
+		partial bool
+	}{
+		{AVSMatch, true, false},
+		{AVSNineDigitZipMatch, true, false},
+		{AVSMaestroMatch, true, false},
+		{AVSAddressOnly, false, true},
+		{AVSZipOnly, false, true},
+		{AVSMaestroPartialMatch, false, true},
+		{AVSNoMatch, false, false},
+		{AVSUnavailable, false, false},
+		{"", false, false},
+	}
+	for _, test := range tests {
+		if got := test.result.IsMatch(); got != test.match {
+			t.Errorf("%q.IsMatch() = %t, want %t", test.result, got, test.match)
+		}
+		if got := test.result.IsPartialMatch(); got != test.partial {
+			t.Errorf("%q.IsPartialMatch() = %t, want %t", test.result, got, test.partial)
+		}
+	}
+}
+
+func TestCVVResult(t *testing.T) {
+	tests := []struct {
+		result   CVVResult
+		match    bool
+		mismatch bool
+	}{
+		{CVVMatch, true, false},
+		{CVVMaestroMatch, true, false},
+		{CVVNoMatch, false, true},
+		{CVVMaestroNoMatch, false, true},
+		{CVVNotProcessed, false, false},
+		{CVVUnavailable, false, false},
+	}
+	for _, test := range tests {
+		if got := test.result.IsMatch(); got != test.match {
+			t.Errorf("%q.IsMatch() = %t, want %t", test.result, got, test.match)
+		}
+		if got := test.result.IsMismatch(); got != test.mismatch {
+			t.Errorf("%q.IsMismatch() = %t, want %t", test.result, got, test.mismatch)
+		}
+	}
+}
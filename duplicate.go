@@ -0,0 +1,57 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// IsDuplicate reports whether PayPal rejected a call as a repeat of one it
+// already processed, by invoice id (10412) or by MSGSUBID (11607).
+func IsDuplicate(err error) bool {
+	return errors.Is(err, ErrDuplicateInvoice) || errors.Is(err, ErrDuplicateRequest)
+}
+
+// Duplicate reports whether PayPal answered a call carrying a MSGSUBID with
+// the response of the original call, which it flags with warning 11607.
+func (r *PayPalResponse) Duplicate() bool {
+	return r.HasWarning(string(CodeDuplicateRequest))
+}
+
+// FindTransactionByInvoice returns the most recent payment made since the
+// given time for the invoice id.
+func (pClient *PayPalClient) FindTransactionByInvoice(invoiceNumber string, since time.Time) (*TransactionDetails, error) {
+	return pClient.FindTransactionByInvoiceCtx(context.Background(), invoiceNumber, since)
+}
+
+func (pClient *PayPalClient) FindTransactionByInvoiceCtx(ctx context.Context, invoiceNumber string, since time.Time) (*TransactionDetails, error) {
+	search, err := pClient.TransactionSearchCtx(ctx, TransactionSearchFilter{StartDate: since, InvoiceNumber: invoiceNumber})
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range search.Results {
+		if result.Type == "Payment" {
+			return pClient.GetTransactionDetailsCtx(ctx, result.TransactionId)
+		}
+	}
+	return nil, errors.New("paypal: no payment found for invoice " + invoiceNumber)
+}
+
+// RecoverDuplicateInvoice turns a duplicate invoice error from a retried
+// charge into the transaction of the original charge. Any other error is
+// returned as is.
+//
+//	response, err := client.DoExpressCheckout(request)
+//	if errors.Is(err, paypal.ErrDuplicateInvoice) {
+//		original, err := client.RecoverDuplicateInvoice(err, invoiceNumber, started)
+//	}
+func (pClient *PayPalClient) RecoverDuplicateInvoice(err error, invoiceNumber string, since time.Time) (*TransactionDetails, error) {
+	return pClient.RecoverDuplicateInvoiceCtx(context.Background(), err, invoiceNumber, since)
+}
+
+func (pClient *PayPalClient) RecoverDuplicateInvoiceCtx(ctx context.Context, err error, invoiceNumber string, since time.Time) (*TransactionDetails, error) {
+	if !errors.Is(err, ErrDuplicateInvoice) {
+		return nil, err
+	}
+	return pClient.FindTransactionByInvoiceCtx(ctx, invoiceNumber, since)
+}
@@ -0,0 +1,65 @@
+// Code generated by gen_errorcodes.go; DO NOT EDIT.
+
+package paypal
+
+const (
+	CodeInternalError            ErrorCode = "10001"
+	CodeSecurityError            ErrorCode = "10002"
+	CodeInvalidArgument          ErrorCode = "10004"
+	CodePermissionDenied         ErrorCode = "10007"
+	CodeTransactionRefused       ErrorCode = "10009"
+	CodeInvalidToken             ErrorCode = "10410"
+	CodeTokenExpired             ErrorCode = "10411"
+	CodeDuplicateInvoice         ErrorCode = "10412"
+	CodeTotalsMismatch           ErrorCode = "10413"
+	CodeTokenAlreadyUsed         ErrorCode = "10415"
+	CodeFundingSourceUnavailable ErrorCode = "10417"
+	CodeFundingSourceRequired    ErrorCode = "10422"
+	CodeCurrencyMismatch         ErrorCode = "10444"
+	CodeTemporaryFailure         ErrorCode = "10445"
+	CodeShippingCountryMismatch  ErrorCode = "10474"
+	CodeFundingFailureRedirect   ErrorCode = "10486"
+	CodeInvalidCardNumber        ErrorCode = "10527"
+	CodeRiskCountryFilter        ErrorCode = "10537"
+	CodeRiskMaxAmountFilter      ErrorCode = "10538"
+	CodeRiskDeclined             ErrorCode = "10539"
+	CodeBuyerRestricted          ErrorCode = "10603"
+	CodeBuyerCannotPay           ErrorCode = "10606"
+	CodeInvalidShippingAddress   ErrorCode = "10736"
+	CodeSearchTruncated          ErrorCode = "11002"
+	CodeInvalidBillingAgreement  ErrorCode = "11451"
+	CodeInvalidProfileStatus     ErrorCode = "11556"
+	CodeDuplicateRequest         ErrorCode = "11607"
+	CodeUserAgreementViolation   ErrorCode = "13122"
+)
+
+var errorCodeDescriptions = map[ErrorCode]string{
+	CodeInternalError:            "Internal error at PayPal, retry later",
+	CodeSecurityError:            "API credentials are invalid or not permitted",
+	CodeInvalidArgument:          "A parameter, such as a transaction id, is invalid",
+	CodePermissionDenied:         "The account has no permission for this call",
+	CodeTransactionRefused:       "PayPal refused the refund or transaction",
+	CodeInvalidToken:             "The Express Checkout token is invalid",
+	CodeTokenExpired:             "The Express Checkout session has expired",
+	CodeDuplicateInvoice:         "A payment was already made for this invoice id",
+	CodeTotalsMismatch:           "Item, tax, shipping and handling totals do not add up to the amount",
+	CodeTokenAlreadyUsed:         "A payment was already completed for this token",
+	CodeFundingSourceUnavailable: "The buyer's funding source cannot be used for this payment",
+	CodeFundingSourceRequired:    "The buyer must choose a new funding source",
+	CodeCurrencyMismatch:         "The currency differs from the one of the checkout",
+	CodeTemporaryFailure:         "The transaction cannot be processed at this time, retry later",
+	CodeShippingCountryMismatch:  "The shipping country must match the buyer's country of residence",
+	CodeFundingFailureRedirect:   "The funding source failed, redirect the buyer back to PayPal",
+	CodeInvalidCardNumber:        "The credit card number is invalid",
+	CodeRiskCountryFilter:        "Declined by the country filter of the risk controls",
+	CodeRiskMaxAmountFilter:      "Declined by the maximum amount filter of the risk controls",
+	CodeRiskDeclined:             "Payment declined by the risk controls",
+	CodeBuyerRestricted:          "The buyer's account is restricted",
+	CodeBuyerCannotPay:           "The buyer cannot pay with PayPal, ask for another payment method",
+	CodeInvalidShippingAddress:   "The shipping address is invalid",
+	CodeSearchTruncated:          "More results matched than were returned",
+	CodeInvalidBillingAgreement:  "The billing agreement id is invalid",
+	CodeInvalidProfileStatus:     "The action is not allowed in the recurring profile's status",
+	CodeDuplicateRequest:         "A request with this MSGSUBID was already processed",
+	CodeUserAgreementViolation:   "The transaction violates the PayPal User Agreement",
+}
@@ -0,0 +1,164 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Sentinel errors matching PayPalErrors with the corresponding code through
+// errors.Is, e.g. errors.Is(err, ErrExpiredToken).
+var (
+	ErrInvalidToken        = errors.New("paypal: invalid express checkout token")
+	ErrExpiredToken        = errors.New("paypal: express checkout token expired")
+	ErrDuplicateInvoice    = errors.New("paypal: duplicate invoice id")
+	ErrTotalsMismatch      = errors.New("paypal: payment totals do not match")
+	ErrTokenAlreadyUsed    = errors.New("paypal: payment already completed for token")
+	ErrFundingFailure10486 = errors.New("paypal: funding source failed, redirect the buyer to PayPal")
+	ErrDuplicateRequest    = errors.New("paypal: duplicate request")
+)
+
+// ErrUnknownAccount is wrapped by the errors of calls through ClientPool.For
+// with an alias the pool has no client for.
+var ErrUnknownAccount = errors.New("paypal: unknown account")
+
+var sentinelCodes = map[error]ErrorCode{
+	ErrInvalidToken:        CodeInvalidToken,
+	ErrExpiredToken:        CodeTokenExpired,
+	ErrDuplicateInvoice:    CodeDuplicateInvoice,
+	ErrTotalsMismatch:      CodeTotalsMismatch,
+	ErrTokenAlreadyUsed:    CodeTokenAlreadyUsed,
+	ErrFundingFailure10486: CodeFundingFailureRedirect,
+	ErrDuplicateRequest:    CodeDuplicateRequest,
+}
+
+// Is reports whether any of the errors PayPal returned has the code of the
+// target sentinel error.
+func (e *PayPalError) Is(target error) bool {
+	code, ok := sentinelCodes[target]
+	if !ok {
+		return false
+	}
+	return e.HasCode(code)
+}
+
+func (e *PayPalError) HasCode(code ErrorCode) bool {
+	if e.Code() == code {
+		return true
+	}
+	for _, detail := range e.Errors {
+		if ErrorCode(detail.ErrorCode) == code {
+			return true
+		}
+	}
+	return false
+}
+
+// TransportError is returned when a call did not get a regular NVP response,
+// because the request failed or PayPal answered with a non-200 status.
+// StatusCode is zero when no response was received at all. RetryAfter is the
+// suggested wait before retrying, zero if retrying is pointless, as for
+// canceled calls or unknown hosts.
+type TransportError struct {
+	StatusCode int
+	Endpoint   string
+	Method     string
+	Elapsed    time.Duration
+	RetryAfter time.Duration
+	Err        error
+}
+
+func newTransportError(endpoint, method string, elapsed time.Duration, response *http.Response, err error) *TransportError {
+	e := &TransportError{Endpoint: endpoint, Method: method, Elapsed: elapsed, Err: err}
+	if response != nil {
+		e.StatusCode = response.StatusCode
+	}
+
+	var dnsError *net.DNSError
+	var netError net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+	case errors.As(err, &dnsError):
+		if !dnsError.IsNotFound {
+			e.RetryAfter = 5 * time.Second
+		}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netError) && netError.Timeout():
+		e.RetryAfter = 2 * time.Second
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, io.ErrUnexpectedEOF):
+		e.RetryAfter = time.Second
+	case response != nil && (response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500):
+		e.RetryAfter = 2 * time.Second
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			e.RetryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+	return e
+}
+
+func (e *TransportError) Retryable() bool {
+	return e.RetryAfter > 0
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("paypal: %s to %s failed after %s: %v", e.Method, e.Endpoint, e.Elapsed.Round(time.Millisecond), e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// PartialPaymentError is returned by DoExpressCheckout when some of several
+// parallel payments went through and others failed. The successful payments
+// are not undone, so callers have to either fulfill them or refund them.
+type PartialPaymentError struct {
+	Payments []PaymentInfo
+	Err      error
+}
+
+func newPartialPaymentError(payments []PaymentInfo, err error) *PartialPaymentError {
+	partial := &PartialPaymentError{Payments: payments, Err: err}
+	if len(partial.Succeeded()) == 0 || len(partial.Failed()) == 0 {
+		return nil
+	}
+	return partial
+}
+
+func (e *PartialPaymentError) Succeeded() []PaymentInfo {
+	var payments []PaymentInfo
+	for _, payment := range e.Payments {
+		if !payment.Failed() {
+			payments = append(payments, payment)
+		}
+	}
+	return payments
+}
+
+func (e *PartialPaymentError) Failed() []PaymentInfo {
+	var payments []PaymentInfo
+	for _, payment := range e.Payments {
+		if payment.Failed() {
+			payments = append(payments, payment)
+		}
+	}
+	return payments
+}
+
+func (e *PartialPaymentError) Error() string {
+	failed := e.Failed()
+	reasons := make([]string, len(failed))
+	for i, payment := range failed {
+		reasons[i] = fmt.Sprintf("%s: %s %s", payment.PaymentRequestId, payment.ErrorCode, payment.ShortMessage)
+	}
+	return fmt.Sprintf("paypal: %d of %d payments failed: %s", len(failed), len(e.Payments), strings.Join(reasons, "; "))
+}
+
+func (e *PartialPaymentError) Unwrap() error {
+	return e.Err
+}
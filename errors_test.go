@@ -0,0 +1,75 @@
+package paypal
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestDoExpressCheckoutPartialSuccess(t *testing.T) {
+	body := url.Values{
+		"TOKEN":                               {"EC-5YJ748178G052312W"},
+		"SUCCESSPAGEREDIRECTREQUESTED":        {"false"},
+		"TIMESTAMP":                           {"2014-03-18T11:42:05Z"},
+		"CORRELATIONID":                       {"a63d6d7c5b4a1"},
+		"ACK":                                 {"PartialSuccess"},
+		"VERSION":                             {"84"},
+		"BUILD":                               {"10127230"},
+		"L_ERRORCODE0":                        {"10417"},
+		"L_SHORTMESSAGE0":                     {"Transaction cannot complete."},
+		"L_LONGMESSAGE0":                      {"The transaction cannot complete successfully. Instruct the customer to use an alternative payment method."},
+		"L_SEVERITYCODE0":                     {"Error"},
+		"PAYMENTINFO_0_TRANSACTIONID":         {"8SC04419N1150050W"},
+		"PAYMENTINFO_0_TRANSACTIONTYPE":       {"expresscheckout"},
+		"PAYMENTINFO_0_PAYMENTTYPE":           {"instant"},
+		"PAYMENTINFO_0_ORDERTIME":             {"2014-03-18T11:42:04Z"},
+		"PAYMENTINFO_0_AMT":                   {"10.00"},
+		"PAYMENTINFO_0_FEEAMT":                {"0.59"},
+		"PAYMENTINFO_0_TAXAMT":                {"0.00"},
+		"PAYMENTINFO_0_CURRENCYCODE":          {"USD"},
+		"PAYMENTINFO_0_PAYMENTSTATUS":         {"Completed"},
+		"PAYMENTINFO_0_PENDINGREASON":         {"None"},
+		"PAYMENTINFO_0_REASONCODE":            {"None"},
+		"PAYMENTINFO_0_PROTECTIONELIGIBILITY": {"Eligible"},
+		"PAYMENTINFO_0_SELLERPAYPALACCOUNTID": {"seller-a@example.com"},
+		"PAYMENTINFO_0_PAYMENTREQUESTID":      {"order-1"},
+		"PAYMENTINFO_0_ERRORCODE":             {"0"},
+		"PAYMENTINFO_0_ACK":                   {"Success"},
+		"PAYMENTINFO_1_ERRORCODE":             {"10417"},
+		"PAYMENTINFO_1_SHORTMESSAGE":          {"Transaction cannot complete."},
+		"PAYMENTINFO_1_LONGMESSAGE":           {"The transaction cannot complete successfully. Instruct the customer to use an alternative payment method."},
+		"PAYMENTINFO_1_SEVERITYCODE":          {"Error"},
+		"PAYMENTINFO_1_SELLERPAYPALACCOUNTID": {"seller-b@example.com"},
+		"PAYMENTINFO_1_PAYMENTREQUESTID":      {"order-2"},
+		"PAYMENTINFO_1_ACK":                   {"Failure"},
+	}.Encode()
+	pClient, _ := newTestClient(t, body)
+
+	response, err := pClient.DoExpressCheckout(DoExpressCheckoutRequest{
+		Token:   "EC-5YJ748178G052312W",
+		PayerId: "PAYER",
+		PaymentRequests: []PaymentRequest{
+			{PaymentRequestId: "order-1", SellerAccountId: "seller-a@example.com", Amount: 10, CurrencyCode: "USD", PaymentAction: "Sale"},
+			{PaymentRequestId: "order-2", SellerAccountId: "seller-b@example.com", Amount: 5, CurrencyCode: "USD", PaymentAction: "Sale"},
+		},
+	})
+	var partial *PartialPaymentError
+	if !errors.As(err, &partial) {
+		t.Fatalf("got %v, want a PartialPaymentError", err)
+	}
+	if succeeded := partial.Succeeded(); len(succeeded) != 1 || succeeded[0].PaymentRequestId != "order-1" {
+		t.Errorf("succeeded payments: %+v", succeeded)
+	}
+	if failed := partial.Failed(); len(failed) != 1 || failed[0].PaymentRequestId != "order-2" {
+		t.Errorf("failed payments: %+v", failed)
+	}
+	var pError *PayPalError
+	if !errors.As(err, &pError) || pError.ErrorCode != "10417" {
+		t.Errorf("got %v, want it to wrap the PayPalError", err)
+	}
+
+	payment := response.Payment("order-1")
+	if payment == nil || payment.Ack != AckSuccess || len(payment.ErrorCode) != 0 || payment.Failed() {
+		t.Errorf("payment order-1: %+v", payment)
+	}
+}
@@ -1,3 +1,3 @@
 module hacpaka/paypal-express
 
-go 1.16
+go 1.21
@@ -0,0 +1,97 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VerifyIPN posts an Instant Payment Notification body back to PayPal and
+// returns an error unless PayPal confirms it sent the message. The body must
+// be passed exactly as received, since PayPal compares it byte for byte.
+func (pClient *PayPalClient) VerifyIPN(body []byte) error {
+	return pClient.VerifyIPNCtx(context.Background(), body)
+}
+
+func (pClient *PayPalClient) VerifyIPNCtx(ctx context.Context, body []byte) error {
+	endpoint := pClient.ipnUrl
+	if len(endpoint) == 0 {
+		endpoint = IPN_PRODUCTION_URL
+		if pClient.usesSandbox {
+			endpoint = IPN_SANDBOX_URL
+		}
+	}
+
+	payload := append([]byte("cmd=_notify-validate&"), body...)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	pClient.setHeaders(ctx, request)
+
+	response, err := pClient.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	verdict, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(verdict)) != "VERIFIED" {
+		return errors.New("paypal: IPN message could not be verified: " + strings.TrimSpace(string(verdict)))
+	}
+	return nil
+}
+
+// IPNHandler returns an http.Handler that verifies each notification with
+// PayPal before passing its values to handle. Notifications that fail
+// verification or handling are answered with an error status so PayPal
+// retries them.
+func (pClient *PayPalClient) IPNHandler(handle func(values url.Values) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1<<16))
+		if err != nil {
+			http.Error(w, "invalid notification", http.StatusBadRequest)
+			return
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			http.Error(w, "invalid notification", http.StatusBadRequest)
+			return
+		}
+		if err := pClient.VerifyIPNCtx(r.Context(), body); err != nil {
+			http.Error(w, "unverified notification", http.StatusBadRequest)
+			return
+		}
+		if err := handle(values); err != nil {
+			http.Error(w, "notification not processed", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+const ipnDateLayout = "15:04:05 Jan 02, 2006 MST"
+
+// ParseIPNDate parses dates such as payment_date, which IPN messages send in
+// PayPal's local time ("08:13:42 Mar 14, 2021 PDT"), and returns them in UTC.
+func ParseIPNDate(s string) (time.Time, error) {
+	t, err := time.Parse(ipnDateLayout, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch t.Location().String() {
+	case "PST":
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.FixedZone("PST", -8*3600))
+	case "PDT":
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.FixedZone("PDT", -7*3600))
+	}
+	return t.UTC(), nil
+}
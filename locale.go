@@ -0,0 +1,25 @@
+package paypal
+
+// SupportedLocales lists the LOCALECODE values PayPal documents for its
+// checkout pages: country codes, which select the country's default
+// language, and language_COUNTRY locales.
+var SupportedLocales = []string{
+	"AT", "AU", "BE", "BR", "CA", "CH", "CN", "DE", "ES", "FR", "GB", "IT",
+	"NL", "PL", "PT", "RU", "US",
+	"da_DK", "de_AT", "de_CH", "de_DE", "en_AU", "en_CA", "en_GB", "en_US",
+	"es_ES", "es_MX", "fr_BE", "fr_CA", "fr_CH", "fr_FR", "he_IL", "id_ID",
+	"it_IT", "ja_JP", "nl_BE", "nl_NL", "no_NO", "pl_PL", "pt_BR", "pt_PT",
+	"ru_RU", "sv_SE", "th_TH", "tr_TR", "zh_CN", "zh_HK", "zh_TW",
+}
+
+var supportedLocales = func() map[string]bool {
+	locales := make(map[string]bool, len(SupportedLocales))
+	for _, locale := range SupportedLocales {
+		locales[locale] = true
+	}
+	return locales
+}()
+
+func IsSupportedLocale(locale string) bool {
+	return supportedLocales[locale]
+}
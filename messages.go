@@ -0,0 +1,74 @@
+package paypal
+
+import "errors"
+
+// BuyerMessages maps error codes to messages that are safe to show buyers,
+// unlike PayPal's own messages which are meant for developers.
+type BuyerMessages map[ErrorCode]string
+
+const (
+	buyerMessageGeneric     = "Your payment could not be completed. Please try again or choose another payment method."
+	buyerMessageUnreachable = "PayPal could not be reached. Please try again in a moment."
+)
+
+var DefaultBuyerMessages = BuyerMessages{
+	CodeInternalError:            "PayPal is temporarily unavailable. Please try again in a moment.",
+	CodeTemporaryFailure:         "PayPal is temporarily unavailable. Please try again in a moment.",
+	CodeInvalidToken:             "Your PayPal session is invalid. Please start the checkout again.",
+	CodeTokenExpired:             "Your PayPal session has expired. Please start the checkout again.",
+	CodeTokenAlreadyUsed:         "This order has already been paid.",
+	CodeDuplicateInvoice:         "This order has already been paid.",
+	CodeFundingSourceRequired:    "Your funding source was declined, please pick another.",
+	CodeFundingFailureRedirect:   "Your funding source was declined, please pick another.",
+	CodeFundingSourceUnavailable: "Your funding source cannot be used for this payment, please pick another.",
+	CodeCurrencyMismatch:         "The currency of your order changed. Please start the checkout again.",
+	CodeShippingCountryMismatch:  "PayPal cannot ship to this country for your account. Please choose another address.",
+	CodeInvalidShippingAddress:   "Your shipping address is invalid. Please check it and try again.",
+	CodeInvalidCardNumber:        "Your card number is invalid. Please check it and try again.",
+	CodeBuyerRestricted:          "Your PayPal account cannot be used for this payment. Please choose another payment method.",
+	CodeBuyerCannotPay:           "Your PayPal account cannot be used for this payment. Please choose another payment method.",
+	CodeRiskDeclined:             buyerMessageGeneric,
+	CodeRiskCountryFilter:        buyerMessageGeneric,
+	CodeRiskMaxAmountFilter:      buyerMessageGeneric,
+}
+
+// With returns a copy of the messages with the overrides applied, so an app
+// can reword or translate some messages and keep the defaults for the rest.
+func (m BuyerMessages) With(overrides BuyerMessages) BuyerMessages {
+	merged := BuyerMessages{}
+	for code, message := range m {
+		merged[code] = message
+	}
+	for code, message := range overrides {
+		merged[code] = message
+	}
+	return merged
+}
+
+// Message returns the message for the first known code of a PayPalError, a
+// generic message for other errors and an empty string for a nil error.
+func (m BuyerMessages) Message(err error) string {
+	if err == nil {
+		return ""
+	}
+	var pError *PayPalError
+	if errors.As(err, &pError) {
+		if message, ok := m[pError.Code()]; ok {
+			return message
+		}
+		for _, detail := range pError.Errors {
+			if message, ok := m[ErrorCode(detail.ErrorCode)]; ok {
+				return message
+			}
+		}
+	}
+	var tError *TransportError
+	if errors.As(err, &tError) {
+		return buyerMessageUnreachable
+	}
+	return buyerMessageGeneric
+}
+
+func BuyerMessage(err error) string {
+	return DefaultBuyerMessages.Message(err)
+}
@@ -0,0 +1,47 @@
+package paypal
+
+import (
+	"errors"
+	"time"
+)
+
+// CallMetrics describes a finished call attempt. ErrorCode is the PayPal error
+// code, "transport" for a TransportError, "other" for any other error and
+// empty on success.
+type CallMetrics struct {
+	Method    string
+	Ack       Ack
+	ErrorCode string
+	Latency   time.Duration
+	Err       error
+}
+
+// Metrics is notified of every call attempt, see the paypalprom package for a
+// Prometheus implementation.
+type Metrics interface {
+	ObserveCall(call CallMetrics)
+}
+
+func WithMetrics(metrics Metrics) Option {
+	return func(pClient *PayPalClient) {
+		pClient.metrics = metrics
+	}
+}
+
+func newCallMetrics(method string, latency time.Duration, response *PayPalResponse, err error) CallMetrics {
+	call := CallMetrics{Method: method, Latency: latency, Err: err}
+	if response != nil {
+		call.Ack = response.Ack
+	}
+	var pError *PayPalError
+	var tError *TransportError
+	switch {
+	case errors.As(err, &pError):
+		call.ErrorCode = pError.ErrorCode
+	case errors.As(err, &tError):
+		call.ErrorCode = "transport"
+	case err != nil:
+		call.ErrorCode = "other"
+	}
+	return call
+}
@@ -0,0 +1,21 @@
+package paypal
+
+import (
+	"context"
+	"net/url"
+)
+
+// Caller performs an NVP call, see PayPalClient.PerformRequestCtx.
+type Caller func(ctx context.Context, values url.Values) (*PayPalResponse, error)
+
+// Middleware wraps a Caller to observe or alter calls, for logging, metrics,
+// tracing or custom retries. It may change values before calling next.
+type Middleware func(next Caller) Caller
+
+// WithMiddleware appends middleware to the client's chain. The first one is
+// the outermost.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(pClient *PayPalClient) {
+		pClient.middleware = append(pClient.middleware, middleware...)
+	}
+}
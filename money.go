@@ -0,0 +1,103 @@
+package paypal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in the minor unit of its currency, cents for USD and
+// yen for JPY, so parsed amounts can be summed and stored without rounding.
+type Money struct {
+	Value        int64
+	CurrencyCode string
+}
+
+// Currencies PayPal does not accept decimal amounts for.
+var zeroDecimalCurrencies = map[string]bool{
+	"HUF": true,
+	"JPY": true,
+	"TWD": true,
+}
+
+func currencyDecimals(currencyCode string) int {
+	if zeroDecimalCurrencies[strings.ToUpper(currencyCode)] {
+		return 0
+	}
+	return 2
+}
+
+// ParseMoney parses a decimal amount as sent by PayPal ("12.34") without going
+// through float64.
+func ParseMoney(amount, currencyCode string) (Money, error) {
+	decimals := currencyDecimals(currencyCode)
+	s := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if len(whole) == 0 || !isDigits(whole) || (len(fraction) != 0 && !isDigits(fraction)) {
+		return Money{CurrencyCode: currencyCode}, errors.New("paypal: invalid amount " + amount)
+	}
+	if len(fraction) > decimals {
+		if strings.Trim(fraction[decimals:], "0") != "" {
+			return Money{CurrencyCode: currencyCode}, fmt.Errorf("paypal: amount %s has more than %d decimals", amount, decimals)
+		}
+		fraction = fraction[:decimals]
+	}
+	fraction += strings.Repeat("0", decimals-len(fraction))
+
+	value, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{CurrencyCode: currencyCode}, errors.New("paypal: invalid amount " + amount)
+	}
+	if negative {
+		value = -value
+	}
+	return Money{value, currencyCode}, nil
+}
+
+// NewMoney converts a float amount, rounding it to the minor unit.
+func NewMoney(amount float64, currencyCode string) Money {
+	scale := math.Pow10(currencyDecimals(currencyCode))
+	return Money{int64(math.Round(amount * scale)), currencyCode}
+}
+
+// Add and Sub assume both amounts are in the same currency.
+func (m Money) Add(other Money) Money {
+	return Money{m.Value + other.Value, m.CurrencyCode}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{m.Value - other.Value, m.CurrencyCode}
+}
+
+func (m Money) Float64() float64 {
+	return float64(m.Value) / math.Pow10(currencyDecimals(m.CurrencyCode))
+}
+
+// String formats the amount the way NVP requests expect it, without the
+// currency.
+func (m Money) String() string {
+	decimals := currencyDecimals(m.CurrencyCode)
+	if decimals == 0 {
+		return strconv.FormatInt(m.Value, 10)
+	}
+	value, sign := m.Value, ""
+	if value < 0 {
+		value, sign = -value, "-"
+	}
+	scale := int64(math.Pow10(decimals))
+	return fmt.Sprintf("%s%d.%0*d", sign, value/scale, decimals, value%scale)
+}
+
+func parseMoney(values url.Values, key, currencyCode string) Money {
+	money, _ := ParseMoney(values.Get(key), currencyCode)
+	return money
+}
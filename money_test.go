@@ -0,0 +1,88 @@
+package paypal
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestExpressCheckoutDetailsJPY(t *testing.T) {
+	body := url.Values{
+		"ACK":                                {"Success"},
+		"TOKEN":                              {"EC-2FD12345GH678901J"},
+		"PAYMENTREQUEST_0_CURRENCYCODE":      {"JPY"},
+		"PAYMENTREQUEST_0_AMT":               {"3500"},
+		"PAYMENTREQUEST_0_ITEMAMT":           {"3000"},
+		"PAYMENTREQUEST_0_TAXAMT":            {"0"},
+		"PAYMENTREQUEST_0_SHIPPINGAMT":       {"500"},
+		"L_PAYMENTREQUEST_0_NAME0":           {"Sencha"},
+		"L_PAYMENTREQUEST_0_QTY0":            {"2"},
+		"L_PAYMENTREQUEST_0_AMT0":            {"1500"},
+		"SHIPPINGOPTIONNAME":                 {"Yamato"},
+		"SHIPPINGOPTIONAMOUNT":               {"500"},
+		"SHIPPINGOPTIONISDEFAULT":            {"true"},
+		"PAYMENTREQUEST_0_SHIPTOCOUNTRYCODE": {"JP"},
+	}.Encode()
+	pClient, _ := newTestClient(t, body)
+
+	details, err := pClient.GetExpressCheckoutDetails("EC-2FD12345GH678901J")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details.PaymentRequests) != 1 || len(details.PaymentRequests[0].Items) != 1 || details.ShippingOption == nil {
+		t.Fatalf("details: %+v", details)
+	}
+	request := details.PaymentRequests[0]
+	for name, test := range map[string]struct {
+		got  Money
+		want Money
+	}{
+		"Amount":                 {request.Amount, Money{3500, "JPY"}},
+		"ItemAmount":             {request.ItemAmount, Money{3000, "JPY"}},
+		"ShippingAmount":         {request.ShippingAmount, Money{500, "JPY"}},
+		"Items[0].Amount":        {request.Items[0].Amount, Money{1500, "JPY"}},
+		"ShippingOption":         {details.ShippingOption.Amount, Money{500, "JPY"}},
+		"details.ShippingAmount": {details.ShippingAmount, Money{500, "JPY"}},
+	} {
+		if test.got != test.want {
+			t.Errorf("%s = %+v, want %+v", name, test.got, test.want)
+		}
+	}
+}
+
+func TestTransactionDetailsSettleAmountJPY(t *testing.T) {
+	body := url.Values{
+		"ACK":           {"Success"},
+		"TRANSACTIONID": {"9AB12345CD678901E"},
+		"CURRENCYCODE":  {"USD"},
+		"AMT":           {"20.00"},
+		"FEEAMT":        {"0.88"},
+		"SETTLEAMT":     {"2814"},
+		"EXCHANGERATE":  {"147.189"},
+		"L_NAME0":       {"Mug"},
+		"L_QTY0":        {"1"},
+		"L_AMT0":        {"20.00"},
+	}.Encode()
+	pClient, _ := newTestClient(t, body, WithSettlementCurrency("JPY"))
+
+	details, err := pClient.GetTransactionDetails("9AB12345CD678901E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := details.PaymentInfo.SettleAmount, (Money{2814, "JPY"}); got != want {
+		t.Errorf("SettleAmount = %+v, want %+v", got, want)
+	}
+	if len(details.Items) != 1 || details.Items[0].Amount != (Money{2000, "USD"}) {
+		t.Errorf("Items = %+v", details.Items)
+	}
+}
+
+func TestPaymentInfoSettleAmountUnconverted(t *testing.T) {
+	payment := parsePaymentInfo(url.Values{
+		"CURRENCYCODE": {"JPY"},
+		"AMT":          {"1500"},
+		"SETTLEAMT":    {"1440"},
+	}, "", "")
+	if got, want := payment.SettleAmount, (Money{1440, "JPY"}); got != want {
+		t.Errorf("SettleAmount = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,403 @@
+package paypal
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshal decodes an NVP response into the struct pointed to by v. Fields
+// are mapped to keys with `nvp:"KEY"` tags; untagged fields are ignored
+// unless they are embedded structs.
+//
+// A {n} in a tag stands for the list index, so a []string tagged
+// `nvp:"L_NAME{n}"` collects L_NAME0, L_NAME1, ... until a key is missing.
+// Tags of slices must contain {n}. A struct field's tag is a prefix for the
+// keys of its own fields, and for a slice of structs the prefix is indexed
+// (`nvp:"PAYMENTREQUEST_{n}_"`). A * in a prefix marks where the inner key
+// goes (`nvp:"L_*{n}"`). Keys starting with L_ keep it in front of any
+// enclosing prefix, as PayPal does (a NAME{n} item inside PAYMENTREQUEST_0_
+// decodes from L_PAYMENTREQUEST_0_NAME0).
+//
+// Money fields take their currency from the CURRENCYCODE key next to them, or
+// from the key named by a currency option (`nvp:"SETTLEAMT,currency=CURRENCY"`).
+func Unmarshal(values url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("paypal: Unmarshal requires a non-nil pointer to a struct")
+	}
+	_, err := decodeStruct(values, rv.Elem(), func(key string) string { return key })
+	return err
+}
+
+// Decode unmarshals the response values into v, see Unmarshal.
+func (r *PayPalResponse) Decode(v interface{}) error {
+	return Unmarshal(r.Values, v)
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	moneyType           = reflect.TypeOf(Money{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func nvpTag(field reflect.StructField) (name string, options string) {
+	tag := field.Tag.Get("nvp")
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+func prefixedKey(prefix, key string) string {
+	if strings.HasPrefix(key, "L_") && !strings.HasPrefix(prefix, "L_") {
+		return "L_" + prefix + key[2:]
+	}
+	return prefix + key
+}
+
+// currencyKey returns the key holding the currency of a Money field.
+func currencyKey(options string) string {
+	for _, option := range strings.Split(options, ",") {
+		if strings.HasPrefix(option, "currency=") {
+			return strings.TrimPrefix(option, "currency=")
+		}
+	}
+	return "CURRENCYCODE"
+}
+
+const indexPlaceholder = "{n}"
+
+func indexedKey(pattern string, index int) string {
+	return strings.Replace(pattern, indexPlaceholder, strconv.Itoa(index), -1)
+}
+
+func checkIndexed(name string, ft reflect.Type) error {
+	if !strings.Contains(name, indexPlaceholder) {
+		return fmt.Errorf("paypal: tag %q of %s lacks the %s index placeholder", name, ft, indexPlaceholder)
+	}
+	return nil
+}
+
+func nestedKey(key func(string) string, prefix string) func(string) string {
+	if strings.Contains(prefix, "*") {
+		return func(name string) string { return key(strings.Replace(prefix, "*", name, 1)) }
+	}
+	return func(name string) string { return key(prefixedKey(prefix, name)) }
+}
+
+func isScalar(t reflect.Type) bool {
+	if t == timeType || t == moneyType || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	return t.Kind() != reflect.Struct && t.Kind() != reflect.Slice && t.Kind() != reflect.Ptr
+}
+
+func decodeStruct(values url.Values, rv reflect.Value, key func(string) string) (bool, error) {
+	found := false
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, options := nvpTag(field)
+		if name == "-" || (len(field.PkgPath) != 0 && !field.Anonymous) {
+			continue
+		}
+		if len(name) == 0 && !field.Anonymous {
+			continue
+		}
+		fieldFound, err := decodeField(values, rv.Field(i), name, options, key)
+		if err != nil {
+			return found, err
+		}
+		found = found || fieldFound
+	}
+	return found, nil
+}
+
+func decodeField(values url.Values, fv reflect.Value, name, options string, key func(string) string) (bool, error) {
+	if !fv.CanSet() {
+		return false, nil
+	}
+	ft := fv.Type()
+	switch {
+	case isScalar(ft):
+		k := key(name)
+		if _, ok := values[k]; !ok {
+			return false, nil
+		}
+		if ft == moneyType {
+			return true, decodeMoney(values.Get(k), values.Get(key(currencyKey(options))), fv, k)
+		}
+		return true, decodeScalar(values.Get(k), fv, k)
+	case ft.Kind() == reflect.Ptr:
+		elem := reflect.New(ft.Elem())
+		found, err := decodeField(values, elem.Elem(), name, options, key)
+		if found {
+			fv.Set(elem)
+		}
+		return found, err
+	case ft.Kind() == reflect.Struct:
+		return decodeStruct(values, fv, nestedKey(key, name))
+	case ft.Kind() == reflect.Slice:
+		if err := checkIndexed(name, ft); err != nil {
+			return false, err
+		}
+		slice := reflect.MakeSlice(ft, 0, 0)
+		// Every element takes at least one key of its own.
+		for index := 0; index < len(values); index++ {
+			elem := reflect.New(ft.Elem()).Elem()
+			var found bool
+			var err error
+			if isScalar(ft.Elem()) {
+				found, err = decodeField(values, elem, indexedKey(name, index), options, key)
+			} else {
+				found, err = decodeField(values, elem, "", "", nestedKey(key, indexedKey(name, index)))
+			}
+			if err != nil {
+				return false, err
+			}
+			if !found {
+				break
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		if slice.Len() == 0 {
+			return false, nil
+		}
+		fv.Set(slice)
+		return true, nil
+	}
+	return false, nil
+}
+
+func decodeMoney(amount, currencyCode string, fv reflect.Value, key string) error {
+	if len(amount) == 0 {
+		return nil
+	}
+	money, err := ParseMoney(amount, currencyCode)
+	if err != nil {
+		return fmt.Errorf("paypal: cannot decode %s: %v", key, err)
+	}
+	fv.Set(reflect.ValueOf(money))
+	return nil
+}
+
+func decodeScalar(s string, fv reflect.Value, key string) error {
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		if err := fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("paypal: cannot decode %s: %v", key, err)
+		}
+		return nil
+	}
+	if len(s) == 0 {
+		return nil
+	}
+	if fv.Type() == timeType {
+		t, err := time.Parse(nvpDateLayout, s)
+		if err != nil {
+			t, err = time.Parse(time.RFC3339, s)
+		}
+		if err != nil {
+			return fmt.Errorf("paypal: cannot decode %s: %v", key, err)
+		}
+		fv.Set(reflect.ValueOf(t.UTC()))
+		return nil
+	}
+
+	var err error
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		switch strings.ToLower(s) {
+		case "1", "true", "yes":
+			fv.SetBool(true)
+		case "0", "false", "no":
+			fv.SetBool(false)
+		default:
+			err = fmt.Errorf("invalid boolean %q", s)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, fv.Type().Bits()); err == nil {
+			fv.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, fv.Type().Bits()); err == nil {
+			fv.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, fv.Type().Bits()); err == nil {
+			fv.SetFloat(f)
+		}
+	default:
+		err = fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("paypal: cannot decode %s: %v", key, err)
+	}
+	return nil
+}
+
+// Marshal encodes the struct v, or a pointer to it, into NVP request values
+// using the same `nvp` tags as Unmarshal. Floats are formatted with two
+// decimals, booleans as 1 and 0 and times in UTC. Money is formatted for its
+// currency, which is also set under the field's currency key unless another
+// field provides it. Fields tagged omitempty are left out when they hold their
+// zero value.
+func Marshal(v interface{}) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("paypal: Marshal requires a struct or a non-nil pointer to one")
+	}
+	values := url.Values{}
+	if err := encodeStruct(values, rv, func(key string) string { return key }); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func encodeStruct(values url.Values, rv reflect.Value, key func(string) string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, options := nvpTag(field)
+		if name == "-" || (len(field.PkgPath) != 0 && !field.Anonymous) {
+			continue
+		}
+		if len(name) == 0 && !field.Anonymous {
+			continue
+		}
+		fv := rv.Field(i)
+		if strings.Contains(","+options+",", ",omitempty,") && fv.IsZero() {
+			continue
+		}
+		if err := encodeField(values, fv, name, options, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeField(values url.Values, fv reflect.Value, name, options string, key func(string) string) error {
+	ft := fv.Type()
+	switch {
+	case ft.Implements(textMarshalerType) && (ft.Kind() != reflect.Ptr || !fv.IsNil()):
+		text, err := fv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return fmt.Errorf("paypal: cannot encode %s: %v", key(name), err)
+		}
+		values.Set(key(name), string(text))
+	case ft == timeType:
+		values.Set(key(name), fv.Interface().(time.Time).UTC().Format(nvpDateLayout))
+	case ft == moneyType:
+		money := fv.Interface().(Money)
+		values.Set(key(name), money.String())
+		if k := key(currencyKey(options)); len(values.Get(k)) == 0 && len(money.CurrencyCode) != 0 {
+			values.Set(k, money.CurrencyCode)
+		}
+	case ft.Kind() == reflect.Ptr:
+		if fv.IsNil() {
+			return nil
+		}
+		return encodeField(values, fv.Elem(), name, options, key)
+	case ft.Kind() == reflect.Struct:
+		return encodeStruct(values, fv, nestedKey(key, name))
+	case ft.Kind() == reflect.Slice:
+		if err := checkIndexed(name, ft); err != nil {
+			return err
+		}
+		for index := 0; index < fv.Len(); index++ {
+			var err error
+			if isScalar(ft.Elem()) {
+				err = encodeField(values, fv.Index(index), indexedKey(name, index), options, key)
+			} else {
+				err = encodeField(values, fv.Index(index), "", "", nestedKey(key, indexedKey(name, index)))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		var s string
+		switch fv.Kind() {
+		case reflect.String:
+			s = fv.String()
+		case reflect.Bool:
+			s = "0"
+			if fv.Bool() {
+				s = "1"
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s = strconv.FormatInt(fv.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s = strconv.FormatUint(fv.Uint(), 10)
+		case reflect.Float32, reflect.Float64:
+			s = fmt.Sprintf("%.2f", fv.Float())
+		default:
+			return fmt.Errorf("paypal: cannot encode %s: unsupported type %s", key(name), ft)
+		}
+		values.Set(key(name), s)
+	}
+	return nil
+}
+
+// ValueScope reads response values under a key prefix such as
+// PAYMENTREQUEST_0_, applying PayPal's naming rules so callers do not have to
+// build keys themselves.
+type ValueScope struct {
+	values url.Values
+	prefix string
+}
+
+func (r *PayPalResponse) Get(key string) string {
+	return ValueScope{r.Values, ""}.Get(key)
+}
+
+// GetIndexed returns the values of key0, key1, ... up to the first missing
+// index, e.g. every L_ERRORCODEn.
+func (r *PayPalResponse) GetIndexed(key string) []string {
+	return ValueScope{r.Values, ""}.GetIndexed(key)
+}
+
+func (r *PayPalResponse) Prefix(prefix string) ValueScope {
+	return ValueScope{r.Values, prefix}
+}
+
+func (r *PayPalResponse) PaymentRequest(n int) ValueScope {
+	return r.Prefix(paymentRequestPrefix(n))
+}
+
+// Get returns the value of key within the scope. Keys starting with L_ keep it
+// in front, so GetIndexed("L_NAME") on PaymentRequest(0) reads
+// L_PAYMENTREQUEST_0_NAME0, L_PAYMENTREQUEST_0_NAME1, ...
+func (s ValueScope) Get(key string) string {
+	return s.values.Get(prefixedKey(s.prefix, key))
+}
+
+func (s ValueScope) GetIndexed(key string) []string {
+	var list []string
+	for i := 0; ; i++ {
+		value, ok := s.values[prefixedKey(s.prefix, key+strconv.Itoa(i))]
+		if !ok {
+			return list
+		}
+		list = append(list, value[0])
+	}
+}
+
+func (s ValueScope) Prefix(prefix string) ValueScope {
+	return ValueScope{s.values, prefixedKey(s.prefix, prefix)}
+}
@@ -0,0 +1,110 @@
+package paypal
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalSliceWithoutPlaceholder(t *testing.T) {
+	var v struct {
+		Names []string `nvp:"TAG"`
+	}
+	err := Unmarshal(url.Values{"TAG": {"a"}}, &v)
+	if err == nil || !strings.Contains(err.Error(), "{n}") {
+		t.Fatalf("got %v, want an error about the missing placeholder", err)
+	}
+	v.Names = []string{"a"}
+	if _, err := Marshal(v); err == nil {
+		t.Fatal("Marshal accepted a slice tag without placeholder")
+	}
+}
+
+func TestUnmarshalIndexedRoundTrip(t *testing.T) {
+	type item struct {
+		Name   string  `nvp:"NAME"`
+		Amount float64 `nvp:"AMT"`
+	}
+	type request struct {
+		Currency string `nvp:"CURRENCYCODE"`
+		Items    []item `nvp:"L_*{n}"`
+	}
+	type order struct {
+		Notes    []string  `nvp:"L_Note{n}"`
+		Requests []request `nvp:"PAYMENTREQUEST_{n}_"`
+	}
+	in := order{
+		Notes: []string{"gift", "fragile"},
+		Requests: []request{
+			{"EUR", []item{{"mug", 9.5}, {"tea", 4}}},
+			{"USD", []item{{"book", 20}}},
+		},
+	}
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"L_Note0", "L_Note1", "PAYMENTREQUEST_1_CURRENCYCODE", "L_PAYMENTREQUEST_0_NAME1", "L_PAYMENTREQUEST_1_AMT0"} {
+		if _, ok := values[key]; !ok {
+			t.Errorf("missing %s in %v", key, values)
+		}
+	}
+
+	var out order
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestMoneyRoundTrip(t *testing.T) {
+	type request struct {
+		Amount      Money   `nvp:"AMT"`
+		ItemAmounts []Money `nvp:"L_AMT{n}"`
+	}
+	type payment struct {
+		Requests     []request `nvp:"PAYMENTREQUEST_{n}_"`
+		SettleAmount *Money    `nvp:"SETTLEAMT,currency=SETTLECURRENCYCODE"`
+	}
+	in := payment{
+		Requests: []request{
+			{Money{1250, "USD"}, []Money{{1000, "USD"}, {250, "USD"}}},
+			{Money{1500, "JPY"}, []Money{{1500, "JPY"}}},
+		},
+		SettleAmount: &Money{1168, "EUR"},
+	}
+	values, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, values, map[string]string{
+		"PAYMENTREQUEST_0_AMT":          "12.50",
+		"PAYMENTREQUEST_0_CURRENCYCODE": "USD",
+		"L_PAYMENTREQUEST_0_AMT1":       "2.50",
+		"PAYMENTREQUEST_1_AMT":          "1500",
+		"PAYMENTREQUEST_1_CURRENCYCODE": "JPY",
+		"SETTLEAMT":                     "11.68",
+		"SETTLECURRENCYCODE":            "EUR",
+	})
+
+	var out payment
+	if err := Unmarshal(values, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestUnmarshalMoneyInvalid(t *testing.T) {
+	var v struct {
+		Amount Money `nvp:"AMT"`
+	}
+	err := Unmarshal(url.Values{"AMT": {"12.345"}, "CURRENCYCODE": {"USD"}}, &v)
+	if err == nil || !strings.Contains(err.Error(), "AMT") {
+		t.Fatalf("got %v, want an error for AMT", err)
+	}
+}
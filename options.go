@@ -0,0 +1,234 @@
+package paypal
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Credentials authenticate the client's calls, either SignatureCredentials or
+// CertificateCredentials.
+type Credentials interface {
+	configure(pClient *PayPalClient)
+}
+
+// SignatureCredentials are the API username, password and signature of the
+// merchant account.
+type SignatureCredentials struct {
+	Username  string
+	Password  string
+	Signature string
+}
+
+func (c SignatureCredentials) configure(pClient *PayPalClient) {
+	pClient.username = c.Username
+	pClient.password = c.Password
+	pClient.signature = c.Signature
+}
+
+// CertificateCredentials authenticate with an API certificate presented as
+// TLS client certificate. Calls then go to the NVP_CERT_ endpoints, and like
+// the options of WithTLSConfig, the certificate is only used when the HTTP
+// client's Transport is an *http.Transport.
+type CertificateCredentials struct {
+	Username    string
+	Password    string
+	Certificate tls.Certificate
+}
+
+// LoadCertificateCredentials reads the API certificate and its private key
+// from PEM files. PayPal's cert_key_pem.txt holds both, so it can be passed
+// as both files.
+func LoadCertificateCredentials(username, password, certFile, keyFile string) (CertificateCredentials, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return CertificateCredentials{}, err
+	}
+	return CertificateCredentials{username, password, certificate}, nil
+}
+
+func (c CertificateCredentials) configure(pClient *PayPalClient) {
+	pClient.username = c.Username
+	pClient.password = c.Password
+	pClient.transport.certificate = &c.Certificate
+}
+
+// Option configures a client in NewClient. Options are not meant to be applied
+// to a client in use.
+type Option func(*PayPalClient)
+
+// Logger receives a line per API call. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+func WithSandbox(usesSandbox bool) Option {
+	return func(pClient *PayPalClient) {
+		pClient.usesSandbox = usesSandbox
+	}
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(pClient *PayPalClient) {
+		pClient.client = client
+	}
+}
+
+// WithEndpoint sends NVP calls to the given URL instead of PayPal's production
+// or sandbox endpoint.
+func WithEndpoint(endpoint string) Option {
+	return func(pClient *PayPalClient) {
+		pClient.endpoint = endpoint
+	}
+}
+
+// WithCheckoutUrl replaces the PayPal page buyers are redirected to by
+// CheckoutUrl and CompleteCheckoutUrl, for instance with a mock server.
+func WithCheckoutUrl(checkoutUrl string) Option {
+	return func(pClient *PayPalClient) {
+		pClient.checkoutUrl = checkoutUrl
+	}
+}
+
+// WithIPNUrl replaces the URL notifications are verified against.
+func WithIPNUrl(ipnUrl string) Option {
+	return func(pClient *PayPalClient) {
+		pClient.ipnUrl = ipnUrl
+	}
+}
+
+// WithVersion selects the NVP API version, NVP_VERSION by default.
+func WithVersion(version string) Option {
+	return func(pClient *PayPalClient) {
+		pClient.version = version
+	}
+}
+
+// WithTimeout bounds each HTTP request, one minute by default. The HTTP
+// client passed to WithHTTPClient is copied rather than modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(pClient *PayPalClient) {
+		pClient.timeout = timeout
+	}
+}
+
+type versionKey struct{}
+
+// ContextWithVersion returns a context making the calls using it request the
+// given API version, for methods needing a newer version than the client's.
+func ContextWithVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, versionKey{}, version)
+}
+
+// WithUserAgent identifies the integration to PayPal, which helps merchant
+// support find its traffic.
+func WithUserAgent(userAgent string) Option {
+	return func(pClient *PayPalClient) {
+		pClient.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a header to every request, including IPN verifications.
+// Content-Type is always set by the client.
+func WithHeader(key, value string) Option {
+	return func(pClient *PayPalClient) {
+		if pClient.header == nil {
+			pClient.header = http.Header{}
+		}
+		pClient.header.Add(key, value)
+	}
+}
+
+type headerKey struct{}
+
+// ContextWithHeader returns a context adding header to the requests of the
+// calls using it, after the headers of WithHeader.
+func ContextWithHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, headerKey{}, header)
+}
+
+func (pClient *PayPalClient) setHeaders(ctx context.Context, request *http.Request) {
+	header, _ := ctx.Value(headerKey{}).(http.Header)
+	for _, h := range []http.Header{pClient.header, header} {
+		for key, values := range h {
+			for _, value := range values {
+				request.Header.Add(key, value)
+			}
+		}
+	}
+	if len(pClient.userAgent) != 0 {
+		request.Header.Set("User-Agent", pClient.userAgent)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+}
+
+func WithLogger(logger Logger) Option {
+	return func(pClient *PayPalClient) {
+		pClient.logger = logger
+	}
+}
+
+// WithRetry makes up to maxAttempts attempts at calls failing with a retryable
+// TransportError, waiting for its RetryAfter in between. PayPal errors are not
+// retried, see WithRetryPolicy for that.
+func WithRetry(maxAttempts int) Option {
+	return WithRetryPolicy(RetryPolicy{MaxAttempts: maxAttempts})
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	policy.RetryableCodes = append([]ErrorCode(nil), policy.RetryableCodes...)
+	return func(pClient *PayPalClient) {
+		pClient.retry = &policy
+	}
+}
+
+// WithRateLimit throttles all calls, for instance with a TokenBucket, so
+// bursts don't trip PayPal's own throttling.
+func WithRateLimit(limiter RateLimiter) Option {
+	return func(pClient *PayPalClient) {
+		pClient.limiter = limiter
+	}
+}
+
+// WithMethodRateLimit throttles calls of one METHOD, such as TransactionSearch,
+// with their own limiter instead of the one of WithRateLimit.
+func WithMethodRateLimit(method string, limiter RateLimiter) Option {
+	return func(pClient *PayPalClient) {
+		if pClient.methodLimiters == nil {
+			pClient.methodLimiters = map[string]RateLimiter{}
+		}
+		pClient.methodLimiters[method] = limiter
+	}
+}
+
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(pClient *PayPalClient) {
+		pClient.breaker = breaker
+	}
+}
+
+// WithSettlementCurrency is the currency payments are converted to when the
+// seller does not hold the payment currency, so SettleAmount can be parsed
+// exactly. PayPal does not return it.
+func WithSettlementCurrency(currencyCode string) Option {
+	return func(pClient *PayPalClient) {
+		pClient.settleCurrency = currencyCode
+	}
+}
+
+func WithButtonSource(code string) Option {
+	return func(pClient *PayPalClient) {
+		pClient.buttonSource.Store(&code)
+	}
+}
+
+func (pClient *PayPalClient) logCall(method string, attempt int, elapsed time.Duration, response *PayPalResponse, err error) {
+	elapsed = elapsed.Round(time.Millisecond)
+	switch {
+	case err != nil:
+		pClient.logger.Printf("paypal: %s attempt %d failed after %s: %v", method, attempt, elapsed, err)
+	case response != nil:
+		pClient.logger.Printf("paypal: %s %s in %s (correlation id %s)", method, response.Ack, elapsed, response.CorrelationId)
+	}
+}
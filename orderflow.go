@@ -0,0 +1,143 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type OrderState int
+
+const (
+	OrderOpen OrderState = iota
+	OrderAuthorized
+	OrderCompleted
+	OrderVoided
+)
+
+func (s OrderState) String() string {
+	switch s {
+	case OrderOpen:
+		return "Open"
+	case OrderAuthorized:
+		return "Authorized"
+	case OrderCompleted:
+		return "Completed"
+	case OrderVoided:
+		return "Voided"
+	}
+	return fmt.Sprintf("OrderState(%d)", int(s))
+}
+
+// OrderFlow sequences the Order -> DoAuthorization -> DoCapture -> DoVoid calls
+// of a ship-later checkout and refuses transitions PayPal would reject. An
+// OrderFlow is not safe for concurrent use.
+type OrderFlow struct {
+	client           *PayPalClient
+	OrderId          string
+	AuthorizationId  string
+	CurrencyCode     string
+	State            OrderState
+	OrderAmount      float64
+	AuthorizedAmount float64
+	CapturedAmount   float64
+}
+
+// StartOrder completes an express checkout with the "Order" payment action and
+// returns a flow positioned on the resulting open order.
+func (pClient *PayPalClient) StartOrder(token, payerId, currencyCode string, amount float64) (*OrderFlow, *DoExpressCheckoutResponse, error) {
+	return pClient.StartOrderCtx(context.Background(), token, payerId, currencyCode, amount)
+}
+
+func (pClient *PayPalClient) StartOrderCtx(ctx context.Context, token, payerId, currencyCode string, amount float64) (*OrderFlow, *DoExpressCheckoutResponse, error) {
+	response, err := pClient.DoExpressCheckoutPaymentCtx(ctx, token, payerId, "Order", currencyCode, amount)
+	if err != nil {
+		return nil, response, err
+	}
+
+	if len(response.Payments) == 0 || len(response.Payments[0].TransactionId) == 0 {
+		return nil, response, errors.New("paypal: order response contains no transaction id")
+	}
+	return &OrderFlow{client: pClient, OrderId: response.Payments[0].TransactionId, CurrencyCode: currencyCode, OrderAmount: amount}, response, nil
+}
+
+// ResumeOrder rebuilds a flow from previously persisted state, for example when
+// the order is authorized or captured by a different process than created it.
+func (pClient *PayPalClient) ResumeOrder(orderId, authorizationId, currencyCode string, state OrderState) *OrderFlow {
+	return &OrderFlow{client: pClient, OrderId: orderId, AuthorizationId: authorizationId, CurrencyCode: currencyCode, State: state}
+}
+
+func (o *OrderFlow) transition(action string, allowed ...OrderState) error {
+	for _, state := range allowed {
+		if o.State == state {
+			return nil
+		}
+	}
+	return fmt.Errorf("paypal: cannot %s an order that is %s", action, o.State)
+}
+
+func (o *OrderFlow) Authorize(amount float64, msgSubId string) (*AuthorizationResponse, error) {
+	return o.AuthorizeCtx(context.Background(), amount, msgSubId)
+}
+
+func (o *OrderFlow) AuthorizeCtx(ctx context.Context, amount float64, msgSubId string) (*AuthorizationResponse, error) {
+	if err := o.transition("authorize", OrderOpen); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, errors.New("paypal: authorization amount must be positive")
+	}
+
+	response, err := o.client.DoAuthorizationCtx(ctx, o.OrderId, amount, o.CurrencyCode, msgSubId)
+	if err == nil {
+		o.State = OrderAuthorized
+		o.AuthorizationId = response.AuthorizationId
+		o.AuthorizedAmount = amount
+	}
+	return response, err
+}
+
+// Capture captures against the current authorization. Unless complete is set
+// the authorization stays open and Capture may be called again.
+func (o *OrderFlow) Capture(amount float64, complete bool, invnum, note string) (*CaptureResponse, error) {
+	return o.CaptureCtx(context.Background(), amount, complete, invnum, note)
+}
+
+func (o *OrderFlow) CaptureCtx(ctx context.Context, amount float64, complete bool, invnum, note string) (*CaptureResponse, error) {
+	if err := o.transition("capture", OrderAuthorized); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, errors.New("paypal: capture amount must be positive")
+	}
+
+	completeType := "NotComplete"
+	if complete {
+		completeType = "Complete"
+	}
+	response, err := o.client.DoCaptureCtx(ctx, o.AuthorizationId, amount, o.CurrencyCode, completeType, invnum, note)
+	if err == nil {
+		o.CapturedAmount += amount
+		if complete {
+			o.State = OrderCompleted
+		}
+	}
+	return response, err
+}
+
+// Void voids the order, which also voids any open authorization against it.
+func (o *OrderFlow) Void(note string) (*VoidResponse, error) {
+	return o.VoidCtx(context.Background(), note)
+}
+
+func (o *OrderFlow) VoidCtx(ctx context.Context, note string) (*VoidResponse, error) {
+	if err := o.transition("void", OrderOpen, OrderAuthorized); err != nil {
+		return nil, err
+	}
+
+	response, err := o.client.DoVoidCtx(ctx, o.OrderId, note)
+	if err == nil {
+		o.State = OrderVoided
+	}
+	return response, err
+}
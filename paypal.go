@@ -1,27 +1,82 @@
 package paypal
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 const (
 	NVP_SANDBOX_URL         = "https://api-3t.sandbox.paypal.com/nvp"
 	NVP_PRODUCTION_URL      = "https://api-3t.paypal.com/nvp"
+	// Endpoints for clients authenticating with an API certificate.
+	NVP_CERT_SANDBOX_URL    = "https://api.sandbox.paypal.com/nvp"
+	NVP_CERT_PRODUCTION_URL = "https://api.paypal.com/nvp"
 	CHECKOUT_SANDBOX_URL    = "https://www.sandbox.paypal.com/cgi-bin/webscr"
 	CHECKOUT_PRODUCTION_URL = "https://www.paypal.com/cgi-bin/webscr"
+	IPN_SANDBOX_URL         = "https://ipnpb.sandbox.paypal.com/cgi-bin/webscr"
+	IPN_PRODUCTION_URL      = "https://ipnpb.paypal.com/cgi-bin/webscr"
+	// NVP_VERSION is the API version used unless a client is created with
+	// WithVersion or a call overrides it, see ContextWithVersion.
 	NVP_VERSION             = "84"
 )
 
+type Ack string
+
+const (
+	AckSuccess            Ack = "Success"
+	AckSuccessWithWarning Ack = "SuccessWithWarning"
+	AckFailure            Ack = "Failure"
+	AckFailureWithWarning Ack = "FailureWithWarning"
+	AckPartialSuccess     Ack = "PartialSuccess"
+)
+
+// Success reports whether the call succeeded, with or without warnings.
+func (a Ack) Success() bool {
+	return strings.EqualFold(string(a), string(AckSuccess)) || strings.EqualFold(string(a), string(AckSuccessWithWarning))
+}
+
+func (a Ack) Failure() bool {
+	return strings.EqualFold(string(a), string(AckFailure)) || strings.EqualFold(string(a), string(AckFailureWithWarning))
+}
+
+// PayPalClient is safe for concurrent use by multiple goroutines, so a single
+// client can serve a whole application. Its configuration is fixed once
+// NewClient returns, but for the BN code of SetButtonSource, and every call
+// works on its own copy of the request values.
 type PayPalClient struct {
 	username string
 	password string
 	signature string
 	usesSandbox bool
 	client *http.Client
+	buttonSource atomic.Pointer[string]
+	endpoint string
+	checkoutUrl string
+	ipnUrl string
+	version string
+	timeout time.Duration
+	userAgent string
+	header http.Header
+	logger Logger
+	retry *RetryPolicy
+	limiter RateLimiter
+	methodLimiters map[string]RateLimiter
+	breaker *CircuitBreaker
+	middleware []Middleware
+	slogger *slog.Logger
+	logLevels *LogLevels
+	metrics Metrics
+	transport transportOptions
+	settleCurrency string
 }
 
 type PayPalDigitalGood struct {
@@ -31,47 +86,167 @@ type PayPalDigitalGood struct {
 }
 
 type PayPalResponse struct {
-	Ack string
+	Ack Ack
 	CorrelationId string
 	Timestamp string
 	Version string
 	Build string
 	Values url.Values
 	usedSandbox bool
+	settleCurrency string
+	checkoutUrl string
 	Invnum string
 	TransactionId string
+	Warnings []PayPalWarning
+	RawBody string
+	StatusCode int
+	Header http.Header
+}
+
+type PayPalWarning struct {
+	ErrorCode string
+	ShortMessage string
+	LongMessage string
 }
 
 type PayPalError struct {
-	Ack string
+	Ack Ack
 	ErrorCode string
 	ShortMessage string
 	LongMessage string
 	SeverityCode string
+	Errors []PayPalErrorDetail
+	CorrelationId string
+	Method string
+	Timestamp string
+}
+
+type PayPalErrorDetail struct {
+	ErrorCode string
+	ShortMessage string
+	LongMessage string
+	SeverityCode string
+}
+
+//go:generate go run gen_errorcodes.go
+
+// ErrorCode is one of PayPal's numeric NVP error codes, see errorcodes.go for
+// the common ones.
+type ErrorCode string
+
+// Description returns a short explanation of well known codes and an empty
+// string otherwise.
+func (c ErrorCode) Description() string {
+	return errorCodeDescriptions[c]
+}
+
+// Code returns the code of the first error PayPal reported.
+func (e *PayPalError) Code() ErrorCode {
+	return ErrorCode(e.ErrorCode)
 }
 
 func (e *PayPalError) Error() string {
 	var message string
 	if len(e.ErrorCode) != 0 && len(e.ShortMessage) != 0 {
 		message = "PayPal Error " + e.ErrorCode + ": " + e.ShortMessage
+		if len(e.Errors) > 1 {
+			message += fmt.Sprintf(" (and %d more)", len(e.Errors)-1)
+		}
 	} else if len(e.Ack) != 0 {
-		message = e.Ack
+		message = string(e.Ack)
 	} else {
 		message = "PayPal is undergoing maintenance.\nPlease try again later."
 	}
 
+	var context []string
+	if len(e.Method) != 0 {
+		context = append(context, e.Method)
+	}
+	if len(e.CorrelationId) != 0 {
+		context = append(context, "correlation id "+e.CorrelationId)
+	}
+	if len(e.Timestamp) != 0 {
+		context = append(context, e.Timestamp)
+	}
+	if len(context) != 0 {
+		message += " (" + strings.Join(context, ", ") + ")"
+	}
+
 	return message
 }
 
+// Time returns the TIMESTAMP of the response in UTC.
+func (r *PayPalResponse) Time() time.Time {
+	return parseTime(r.Values, "TIMESTAMP")
+}
+
+func (r *PayPalResponse) Success() bool {
+	return r.Ack.Success()
+}
+
+// HasWarning reports whether PayPal returned the warning code with an otherwise
+// successful response.
+func (r *PayPalResponse) HasWarning(code string) bool {
+	for _, warning := range r.Warnings {
+		if warning.ErrorCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckoutUrl returns the page to redirect the buyer to after
+// SetExpressCheckout, or an empty string if the response has no TOKEN.
 func (r *PayPalResponse) CheckoutUrl() string {
+	checkoutUrl, _ := r.CheckoutUrlWith(CheckoutUrlOptions{})
+	return checkoutUrl
+}
+
+// CheckoutUrlOptions customize the checkout page. Commit makes PayPal show
+// "Pay Now" and skip the confirmation step on the merchant's site, for flows
+// calling DoExpressCheckoutPayment right after the buyer returns. Locale must
+// be one of SupportedLocales and Country a two letter country code.
+type CheckoutUrlOptions struct {
+	Commit bool
+	Locale string
+	Country string
+}
+
+func (r *PayPalResponse) CheckoutUrlWith(options CheckoutUrlOptions) (string, error) {
+	token := r.Values.Get("TOKEN")
+	if len(token) == 0 {
+		return "", errors.New("paypal: response has no TOKEN to check out")
+	}
 	query := url.Values{}
 	query.Set("cmd", "_express-checkout")
-	query.Add("token", r.Values["TOKEN"][0])
-	checkoutUrl := CHECKOUT_PRODUCTION_URL
+	query.Add("token", token)
+	if options.Commit {
+		query.Set("useraction", "commit")
+	}
+	if len(options.Locale) != 0 {
+		if !IsSupportedLocale(options.Locale) {
+			return "", errors.New("paypal: unsupported locale code " + options.Locale)
+		}
+		query.Set("locale.x", options.Locale)
+	}
+	if len(options.Country) != 0 {
+		country := normalizeCountryCode(options.Country)
+		if len(country) != 2 {
+			return "", errors.New("paypal: invalid country code " + options.Country)
+		}
+		query.Set("country.x", country)
+	}
+	return fmt.Sprintf("%s?%s", r.checkoutBaseUrl(), query.Encode()), nil
+}
+
+func (r *PayPalResponse) checkoutBaseUrl() string {
+	if len(r.checkoutUrl) != 0 {
+		return r.checkoutUrl
+	}
 	if r.usedSandbox {
-		checkoutUrl = CHECKOUT_SANDBOX_URL
+		return CHECKOUT_SANDBOX_URL
 	}
-	return fmt.Sprintf("%s?%s", checkoutUrl, query.Encode())
+	return CHECKOUT_PRODUCTION_URL
 }
 
 func SumPayPalDigitalGoodAmounts(goods *[]PayPalDigitalGood) (sum float64) {
@@ -81,40 +256,190 @@ func SumPayPalDigitalGoodAmounts(goods *[]PayPalDigitalGood) (sum float64) {
 	return
 }
 
+// Deprecated: use NewClient(SignatureCredentials{...}, WithSandbox(usesSandbox)).
 func NewDefaultClient(username, password, signature string, usesSandbox bool) *PayPalClient {
-	return &PayPalClient{username, password, signature, usesSandbox, new(http.Client)}
+	return NewClient(SignatureCredentials{username, password, signature}, WithSandbox(usesSandbox))
+}
+
+// NewClient returns a client for the production API unless configured
+// otherwise through the options. Unless WithHTTPClient is used, requests go
+// through a transport with connect, TLS handshake and response header
+// timeouts and are bounded by an overall timeout of one minute.
+func NewClient(credentials Credentials, opts ...Option) *PayPalClient {
+	pClient := &PayPalClient{version: NVP_VERSION}
+	if credentials != nil {
+		credentials.configure(pClient)
+	}
+	for _, opt := range opts {
+		opt(pClient)
+	}
+	if pClient.client == nil {
+		pClient.client = &http.Client{Transport: newDefaultTransport(), Timeout: defaultTimeout}
+	}
+	if pClient.timeout != 0 {
+		client := *pClient.client
+		client.Timeout = pClient.timeout
+		pClient.client = &client
+	}
+	pClient.configureTransport()
+	return pClient
+}
+
+// SetButtonSource sets the partner build notation (BN) code sent as
+// BUTTONSOURCE with every request that does not already carry one. It may be
+// called while the client is in use; calls already sent keep the old code.
+//
+// Deprecated: use WithButtonSource.
+func (pClient *PayPalClient) SetButtonSource(code string) {
+	pClient.buttonSource.Store(&code)
 }
 
-func NewClient(username, password, signature string, usesSandbox bool, client *http.Client) *PayPalClient {
-	return &PayPalClient{username, password, signature, usesSandbox, client}
+// ButtonSource returns the BN code set by WithButtonSource or SetButtonSource.
+func (pClient *PayPalClient) ButtonSource() string {
+	if code := pClient.buttonSource.Load(); code != nil {
+		return *code
+	}
+	return ""
 }
 
 func (pClient *PayPalClient) PerformRequest(values url.Values) (*PayPalResponse, error) {
+	return pClient.PerformRequestCtx(context.Background(), values)
+}
+
+// PerformRequestCtx is Call with the METHOD taken from values.
+func (pClient *PayPalClient) PerformRequestCtx(ctx context.Context, values url.Values) (*PayPalResponse, error) {
+	return pClient.Call(ctx, values.Get("METHOD"), values)
+}
+
+// Call performs the API call method with params and aborts it once ctx is
+// done, in which case a TransportError wrapping the context's error is
+// returned. Credentials are added to a copy of params, which are left as
+// they are. A VERSION in params takes precedence over the one of ctx, which
+// in turn takes precedence over the client's. Middleware is applied around
+// the call and sees the values before credentials are added.
+func (pClient *PayPalClient) Call(ctx context.Context, method string, params url.Values) (*PayPalResponse, error) {
+	values := make(url.Values, len(params)+1)
+	for key, value := range params {
+		values[key] = append([]string(nil), value...)
+	}
+	values.Set("METHOD", method)
+
+	call := Caller(pClient.perform)
+	for i := len(pClient.middleware) - 1; i >= 0; i-- {
+		call = pClient.middleware[i](call)
+	}
+	return call(ctx, values)
+}
+
+func (pClient *PayPalClient) perform(ctx context.Context, values url.Values) (*PayPalResponse, error) {
 	values.Add("USER", pClient.username)
 	values.Add("PWD", pClient.password)
-	values.Add("SIGNATURE", pClient.signature)
-	values.Add("VERSION", NVP_VERSION)
+	if len(pClient.signature) != 0 {
+		values.Add("SIGNATURE", pClient.signature)
+	}
+	if len(values.Get("VERSION")) == 0 {
+		version, ok := ctx.Value(versionKey{}).(string)
+		if !ok {
+			version = pClient.version
+		}
+		values.Set("VERSION", version)
+	}
+	if buttonSource := pClient.ButtonSource(); len(buttonSource) != 0 && len(values.Get("BUTTONSOURCE")) == 0 {
+		values.Set("BUTTONSOURCE", buttonSource)
+	}
 
-	endpoint := NVP_PRODUCTION_URL
-	if pClient.usesSandbox {
-		endpoint = NVP_SANDBOX_URL
+	endpoint := pClient.endpoint
+	if len(endpoint) == 0 {
+		switch {
+		case pClient.transport.certificate != nil && pClient.usesSandbox:
+			endpoint = NVP_CERT_SANDBOX_URL
+		case pClient.transport.certificate != nil:
+			endpoint = NVP_CERT_PRODUCTION_URL
+		case pClient.usesSandbox:
+			endpoint = NVP_SANDBOX_URL
+		default:
+			endpoint = NVP_PRODUCTION_URL
+		}
 	}
 
-	formResponse, err := pClient.client.PostForm(endpoint, values)
+	limiter := pClient.rateLimiter(values.Get("METHOD"))
+	for attempt := 1; ; attempt++ {
+		// Wait first, a trial call given by the breaker must reach record.
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if pClient.breaker != nil {
+			if err := pClient.breaker.allow(); err != nil {
+				return nil, err
+			}
+		}
+		start := time.Now()
+		response, err := pClient.send(ctx, endpoint, values)
+		elapsed := time.Since(start)
+		if pClient.breaker != nil {
+			pClient.breaker.record(ctx, err)
+		}
+		if pClient.logger != nil {
+			pClient.logCall(values.Get("METHOD"), attempt, elapsed, response, err)
+		}
+		if pClient.slogger != nil {
+			pClient.slogCall(ctx, values, attempt, elapsed, response, err)
+		}
+		if pClient.metrics != nil {
+			pClient.metrics.ObserveCall(newCallMetrics(values.Get("METHOD"), elapsed, response, err))
+		}
+		if pClient.retry == nil {
+			return response, err
+		}
+
+		delay, retry := pClient.retry.delay(attempt, err)
+		if pClient.retry.OnAttempt != nil {
+			pClient.retry.OnAttempt(RetryAttempt{Method: values.Get("METHOD"), Attempt: attempt, Elapsed: elapsed, Err: err, Delay: delay})
+		}
+		if !retry {
+			return response, err
+		}
+		select {
+		case <-ctx.Done():
+			return response, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (pClient *PayPalClient) send(ctx context.Context, endpoint string, values url.Values) (*PayPalResponse, error) {
+	start := time.Now()
+	transportError := func(formResponse *http.Response, err error) *TransportError {
+		return newTransportError(endpoint, values.Get("METHOD"), time.Since(start), formResponse, err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(values.Encode()))
 	if err != nil {
 		return nil, err
 	}
+	pClient.setHeaders(ctx, request)
+
+	formResponse, err := pClient.client.Do(request)
+	if err != nil {
+		return nil, transportError(nil, err)
+	}
 	defer formResponse.Body.Close()
 
 	body, err := ioutil.ReadAll(formResponse.Body)
 	if err != nil {
-		return nil, err
+		return nil, transportError(formResponse, err)
+	}
+
+	response := &PayPalResponse{usedSandbox: pClient.usesSandbox, checkoutUrl: pClient.checkoutUrl, settleCurrency: pClient.settleCurrency, RawBody: string(body), StatusCode: formResponse.StatusCode, Header: formResponse.Header}
+	if formResponse.StatusCode != http.StatusOK {
+		return response, transportError(formResponse, errors.New("unexpected HTTP status "+formResponse.Status))
 	}
 
 	responseValues, err := url.ParseQuery(string(body))
-	response := &PayPalResponse{usedSandbox: pClient.usesSandbox}
 	if err == nil {
-		response.Ack = responseValues.Get("ACK")
+		response.Ack = Ack(responseValues.Get("ACK"))
 		response.CorrelationId = responseValues.Get("CORRELATIONID")
 		response.Timestamp = responseValues.Get("TIMESTAMP")
 		response.Version = responseValues.Get("VERSION")
@@ -124,13 +449,21 @@ func (pClient *PayPalClient) PerformRequest(values url.Values) (*PayPalResponse,
 		response.TransactionId = responseValues.Get("PAYMENTREQUEST_0_TRANSACTIONID")
 
 		errorCode := responseValues.Get("L_ERRORCODE0")
-		if len(errorCode) != 0 || strings.ToLower(response.Ack) == "failure" || strings.ToLower(response.Ack) == "failurewithwarning" {
+		if strings.EqualFold(string(response.Ack), string(AckSuccessWithWarning)) {
+			for _, detail := range parseErrorDetails(responseValues) {
+				response.Warnings = append(response.Warnings, PayPalWarning{detail.ErrorCode, detail.ShortMessage, detail.LongMessage})
+			}
+		} else if len(errorCode) != 0 || response.Ack.Failure() {
 			pError := new(PayPalError)
 			pError.Ack = response.Ack
 			pError.ErrorCode = errorCode
 			pError.ShortMessage = responseValues.Get("L_SHORTMESSAGE0")
 			pError.LongMessage = responseValues.Get("L_LONGMESSAGE0")
 			pError.SeverityCode = responseValues.Get("L_SEVERITYCODE0")
+			pError.Errors = parseErrorDetails(responseValues)
+			pError.CorrelationId = response.CorrelationId
+			pError.Method = values.Get("METHOD")
+			pError.Timestamp = response.Timestamp
 
 			err = pError
 		}
@@ -139,50 +472,100 @@ func (pClient *PayPalClient) PerformRequest(values url.Values) (*PayPalResponse,
 	return response, err
 }
 
-func (pClient *PayPalClient) SetExpressCheckoutDigitalGoods(paymentAmount float64, currencyCode string, returnURL, cancelURL string, invnum string, goods []PayPalDigitalGood) (*PayPalResponse, error) {
-	values := url.Values{}
-	values.Set("METHOD", "SetExpressCheckout")
-	values.Add("PAYMENTREQUEST_0_AMT", fmt.Sprintf("%.2f", paymentAmount))
-	values.Add("PAYMENTREQUEST_0_PAYMENTACTION", "Sale")
-	values.Add("PAYMENTREQUEST_0_CURRENCYCODE", currencyCode)
-	values.Add("PAYMENTREQUEST_0_INVNUM", invnum)
-	values.Add("RETURNURL", returnURL)
-	values.Add("CANCELURL", cancelURL)
-	values.Add("REQCONFIRMSHIPPING", "0")
-	values.Add("NOSHIPPING", "1")
-	values.Add("SOLUTIONTYPE", "Sole")
-
-	for i := 0; i < len(goods); i++ {
-		good := goods[i]
+func (pClient *PayPalClient) SetExpressCheckoutDigitalGoods(paymentAmount float64, currencyCode string, returnURL, cancelURL string, invnum string, goods []PayPalDigitalGood, agreements ...BillingAgreement) (*PayPalResponse, error) {
+	return pClient.SetExpressCheckoutDigitalGoodsCtx(context.Background(), paymentAmount, currencyCode, returnURL, cancelURL, invnum, goods, agreements...)
+}
 
-		values.Add(fmt.Sprintf("%s%d", "L_PAYMENTREQUEST_0_NAME", i), good.Name)
-		values.Add(fmt.Sprintf("%s%d", "L_PAYMENTREQUEST_0_AMT", i), fmt.Sprintf("%.2f", good.Amount))
-		values.Add(fmt.Sprintf("%s%d", "L_PAYMENTREQUEST_0_QTY", i), fmt.Sprintf("%d", good.Quantity))
-		values.Add(fmt.Sprintf("%s%d", "L_PAYMENTREQUEST_0_ITEMCATEGORY", i), "Digital")
+func (pClient *PayPalClient) SetExpressCheckoutDigitalGoodsCtx(ctx context.Context, paymentAmount float64, currencyCode string, returnURL, cancelURL string, invnum string, goods []PayPalDigitalGood, agreements ...BillingAgreement) (*PayPalResponse, error) {
+	items := make([]LineItem, len(goods))
+	for i, good := range goods {
+		items[i] = LineItem{Name: good.Name, Quantity: int(good.Quantity), Amount: good.Amount}
 	}
 
-	return pClient.PerformRequest(values)
+	return pClient.SetExpressCheckoutCtx(ctx, SetExpressCheckoutRequest{
+		ReturnUrl: returnURL,
+		CancelUrl: cancelURL,
+		PaymentRequests: []PaymentRequest{{
+			Amount:        paymentAmount,
+			CurrencyCode:  currencyCode,
+			PaymentAction: "Sale",
+			InvoiceNumber: invnum,
+			Items:         items,
+			ItemCategory:  "Digital",
+		}},
+		BillingAgreements: agreements,
+		NoShipping:        ShippingHidden,
+		SolutionType:      SolutionSole,
+	})
 }
 
-func (pClient *PayPalClient) DoExpressCheckoutSale(token, payerId, currencyCode string, finalPaymentAmount float64) (*PayPalResponse, error) {
-	return pClient.DoExpressCheckoutPayment(token, payerId, "Sale", currencyCode, finalPaymentAmount)
+func (pClient *PayPalClient) DoExpressCheckoutSale(token, payerId, currencyCode string, finalPaymentAmount float64) (*DoExpressCheckoutResponse, error) {
+	return pClient.DoExpressCheckoutSaleCtx(context.Background(), token, payerId, currencyCode, finalPaymentAmount)
 }
 
-func (pClient *PayPalClient) DoExpressCheckoutPayment(token, payerId, paymentType, currencyCode string, finalPaymentAmount float64) (*PayPalResponse, error) {
-	values := url.Values{}
-	values.Set("METHOD", "DoExpressCheckoutPayment")
-	values.Add("TOKEN", token)
-	values.Add("PAYERID", payerId)
-	values.Add("PAYMENTREQUEST_0_PAYMENTACTION", paymentType)
-	values.Add("PAYMENTREQUEST_0_CURRENCYCODE", currencyCode)
-	values.Add("PAYMENTREQUEST_0_AMT", fmt.Sprintf("%.2f", finalPaymentAmount))
+func (pClient *PayPalClient) DoExpressCheckoutSaleCtx(ctx context.Context, token, payerId, currencyCode string, finalPaymentAmount float64) (*DoExpressCheckoutResponse, error) {
+	return pClient.DoExpressCheckoutPaymentCtx(ctx, token, payerId, "Sale", currencyCode, finalPaymentAmount)
+}
+
+func (pClient *PayPalClient) DoExpressCheckoutPayment(token, payerId, paymentType, currencyCode string, finalPaymentAmount float64) (*DoExpressCheckoutResponse, error) {
+	return pClient.DoExpressCheckoutPaymentCtx(context.Background(), token, payerId, paymentType, currencyCode, finalPaymentAmount)
+}
 
-	return pClient.PerformRequest(values)
+func (pClient *PayPalClient) DoExpressCheckoutPaymentCtx(ctx context.Context, token, payerId, paymentType, currencyCode string, finalPaymentAmount float64) (*DoExpressCheckoutResponse, error) {
+	return pClient.DoExpressCheckoutCtx(ctx, DoExpressCheckoutRequest{
+		Token:   token,
+		PayerId: payerId,
+		PaymentRequests: []PaymentRequest{{
+			Amount:        finalPaymentAmount,
+			CurrencyCode:  currencyCode,
+			PaymentAction: paymentType,
+		}},
+	})
 }
 
-func (pClient *PayPalClient) GetExpressCheckoutDetails(token string) (*PayPalResponse, error) {
+func (pClient *PayPalClient) GetExpressCheckoutDetails(token string) (*ExpressCheckoutDetails, error) {
+	return pClient.GetExpressCheckoutDetailsCtx(context.Background(), token)
+}
+
+func (pClient *PayPalClient) GetExpressCheckoutDetailsCtx(ctx context.Context, token string) (*ExpressCheckoutDetails, error) {
 	values := url.Values{}
 	values.Add("TOKEN", token)
 	values.Set("METHOD", "GetExpressCheckoutDetails")
-	return pClient.PerformRequest(values)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return newExpressCheckoutDetails(response), err
+}
+
+func parseErrorDetails(values url.Values) []PayPalErrorDetail {
+	var details []PayPalErrorDetail
+	for i := 0; ; i++ {
+		n := strconv.Itoa(i)
+		if _, ok := values["L_ERRORCODE"+n]; !ok {
+			return details
+		}
+		details = append(details, PayPalErrorDetail{
+			ErrorCode:    values.Get("L_ERRORCODE" + n),
+			ShortMessage: values.Get("L_SHORTMESSAGE" + n),
+			LongMessage:  values.Get("L_LONGMESSAGE" + n),
+			SeverityCode: values.Get("L_SEVERITYCODE" + n),
+		})
+	}
+}
+
+// parseTime parses a date in the NVP format, returning the zero time for
+// missing or malformed values.
+func parseTime(values url.Values, key string) time.Time {
+	t, err := time.Parse(nvpDateLayout, values.Get(key))
+	if err != nil {
+		t, _ = time.Parse(time.RFC3339, values.Get(key))
+	}
+	return t.UTC()
+}
+
+func parseAmount(values url.Values, key string) float64 {
+	amount, _ := strconv.ParseFloat(values.Get(key), 64)
+	return amount
 }
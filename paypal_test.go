@@ -0,0 +1,88 @@
+package paypal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client whose calls are answered with body. The
+// returned func gives the values of the last request.
+func newTestClient(t *testing.T, body string, opts ...Option) (*PayPalClient, func() url.Values) {
+	t.Helper()
+	var mu sync.Mutex
+	var last url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("cannot parse request: %v", err)
+		}
+		mu.Lock()
+		last = r.PostForm
+		mu.Unlock()
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	opts = append([]Option{WithEndpoint(server.URL)}, opts...)
+	pClient := NewClient(SignatureCredentials{"user", "pwd", "sig"}, opts...)
+	return pClient, func() url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+// TestConcurrentCalls is meant to be run with go test -race.
+func TestConcurrentCalls(t *testing.T) {
+	limiter, err := NewTokenBucket(10000, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	breaker, err := NewCircuitBreaker(5, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pClient, _ := newTestClient(t, "ACK=Success&TOKEN=EC-1AB23456CD789012E",
+		WithButtonSource("Shop_Cart_EC"),
+		WithRetry(2),
+		WithRateLimit(limiter),
+		WithCircuitBreaker(breaker),
+		WithHeader("X-Integration", "shop"),
+		WithMiddleware(func(next Caller) Caller { return next }))
+	params := url.Values{"TOKEN": {"EC-1AB23456CD789012E"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			response, err := pClient.Call(context.Background(), "GetExpressCheckoutDetails", params)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(response.CheckoutUrl()) == 0 {
+				t.Error("no checkout URL")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			pClient.SetButtonSource("Shop_Cart_EC")
+		}()
+		go func() {
+			defer wg.Done()
+			if code := pClient.ButtonSource(); code != "Shop_Cart_EC" {
+				t.Errorf("ButtonSource() = %q", code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(params) != 1 || len(params["TOKEN"]) != 1 {
+		t.Errorf("params modified: %v", params)
+	}
+}
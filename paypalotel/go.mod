@@ -0,0 +1,18 @@
+module hacpaka/paypal-express/paypalotel
+
+go 1.21
+
+require (
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+	hacpaka/paypal-express v0.0.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.24.0 // indirect
+)
+
+// The adapter is developed alongside the client it instruments.
+replace hacpaka/paypal-express => ../
@@ -0,0 +1,53 @@
+// Package paypalotel traces paypal client calls with OpenTelemetry.
+package paypalotel
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"hacpaka/paypal-express"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "hacpaka/paypal-express/paypalotel"
+
+// Middleware starts a client span around each call, including its retries,
+// as a child of the span in the caller's context. Spans carry the METHOD, the
+// ack, the correlation id and the PayPal error code. The global tracer
+// provider is used if provider is nil.
+func Middleware(provider trace.TracerProvider) paypal.Middleware {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(tracerName)
+	return func(next paypal.Caller) paypal.Caller {
+		return func(ctx context.Context, values url.Values) (*paypal.PayPalResponse, error) {
+			method := values.Get("METHOD")
+			ctx, span := tracer.Start(ctx, "paypal "+method,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attribute.String("paypal.method", method)))
+			defer span.End()
+
+			response, err := next(ctx, values)
+			if response != nil {
+				span.SetAttributes(
+					attribute.String("paypal.ack", string(response.Ack)),
+					attribute.String("paypal.correlation_id", response.CorrelationId))
+			}
+			var pError *paypal.PayPalError
+			if errors.As(err, &pError) {
+				span.SetAttributes(attribute.String("paypal.error_code", pError.ErrorCode))
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return response, err
+		}
+	}
+}
@@ -0,0 +1,21 @@
+module hacpaka/paypal-express/paypalprom
+
+go 1.21
+
+require (
+	github.com/prometheus/client_golang v1.19.1
+	hacpaka/paypal-express v0.0.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+// The adapter is developed alongside the client it instruments.
+replace hacpaka/paypal-express => ../
@@ -0,0 +1,52 @@
+// Package paypalprom exports paypal client metrics to Prometheus.
+package paypalprom
+
+import (
+	"hacpaka/paypal-express"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements paypal.Metrics with a request counter, an error counter
+// by code and a latency histogram, all labeled by METHOD.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+// New registers the metrics with registerer, prometheus.DefaultRegisterer if
+// nil.
+func New(registerer prometheus.Registerer) *Metrics {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "paypal",
+			Name:      "requests_total",
+			Help:      "PayPal NVP calls by method and ack.",
+		}, []string{"method", "ack"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "paypal",
+			Name:      "errors_total",
+			Help:      "Failed PayPal NVP calls by method and error code.",
+		}, []string{"method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "paypal",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of PayPal NVP calls.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+		}, []string{"method"}),
+	}
+	registerer.MustRegister(m.requests, m.errors, m.latency)
+	return m
+}
+
+func (m *Metrics) ObserveCall(call paypal.CallMetrics) {
+	m.requests.WithLabelValues(call.Method, string(call.Ack)).Inc()
+	m.latency.WithLabelValues(call.Method).Observe(call.Latency.Seconds())
+	if len(call.ErrorCode) != 0 {
+		m.errors.WithLabelValues(call.Method, call.ErrorCode).Inc()
+	}
+}
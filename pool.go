@@ -0,0 +1,83 @@
+package paypal
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ClientPool holds a client per merchant account, keyed by an alias of the
+// platform's choosing, so calls can be routed with
+// pool.For("store-eu").RefundTransaction(...). It is safe for concurrent use.
+type ClientPool struct {
+	mu       sync.RWMutex
+	clients  map[string]*PayPalClient
+	defaults []Option
+}
+
+// NewClientPool returns an empty pool whose clients are created with the
+// given options, before their own.
+func NewClientPool(opts ...Option) *ClientPool {
+	return &ClientPool{clients: map[string]*PayPalClient{}, defaults: opts}
+}
+
+// Add creates the client of an account, replacing any previous one with the
+// same alias.
+func (p *ClientPool) Add(alias string, credentials Credentials, opts ...Option) *PayPalClient {
+	all := append(append([]Option(nil), p.defaults...), opts...)
+	pClient := NewClient(credentials, all...)
+	p.Register(alias, pClient)
+	return pClient
+}
+
+// Register adds a client created by the caller.
+func (p *ClientPool) Register(alias string, pClient *PayPalClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clients[alias] = pClient
+}
+
+func (p *ClientPool) Remove(alias string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.clients, alias)
+}
+
+func (p *ClientPool) Get(alias string) (*PayPalClient, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	pClient, ok := p.clients[alias]
+	return pClient, ok
+}
+
+// For returns the client of alias. For an unknown alias it returns a client
+// whose calls fail with an error wrapping ErrUnknownAccount, so calls can be
+// chained without checking.
+func (p *ClientPool) For(alias string) *PayPalClient {
+	if pClient, ok := p.Get(alias); ok {
+		return pClient
+	}
+	err := fmt.Errorf("%w %q", ErrUnknownAccount, alias)
+	return NewClient(nil, WithHTTPClient(&http.Client{Transport: failingTransport{err}}))
+}
+
+// Aliases returns the aliases of the pool's accounts, sorted.
+func (p *ClientPool) Aliases() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	aliases := make([]string, 0, len(p.clients))
+	for alias := range p.clients {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
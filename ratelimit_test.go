@@ -0,0 +1,42 @@
+package paypal
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewTokenBucketInvalid(t *testing.T) {
+	for _, test := range []struct {
+		rate  float64
+		burst int
+	}{
+		{0, 1},
+		{-1, 1},
+		{math.NaN(), 1},
+		{math.Inf(1), 1},
+		{1, 0},
+		{1, -5},
+	} {
+		if bucket, err := NewTokenBucket(test.rate, test.burst); err == nil {
+			t.Errorf("NewTokenBucket(%v, %d) = %+v, want an error", test.rate, test.burst, bucket)
+		}
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	bucket, err := NewTokenBucket(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := bucket.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("3 calls at 100/s with a burst of 1 took %s", elapsed)
+	}
+}
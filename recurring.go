@@ -0,0 +1,491 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ProfileStatus string
+
+const (
+	ProfileActive    ProfileStatus = "Active"
+	ProfilePending   ProfileStatus = "Pending"
+	ProfileCancelled ProfileStatus = "Cancelled"
+	ProfileSuspended ProfileStatus = "Suspended"
+	ProfileExpired   ProfileStatus = "Expired"
+)
+
+// ParseProfileStatus accepts both the statuses of profile details and IPN
+// messages ("Active") and those returned on profile creation ("ActiveProfile").
+// Unknown statuses are returned as is together with an error.
+func ParseProfileStatus(s string) (ProfileStatus, error) {
+	status := ProfileStatus(strings.TrimSuffix(s, "Profile"))
+	switch status {
+	case ProfileActive, ProfilePending, ProfileCancelled, ProfileSuspended, ProfileExpired:
+		return status, nil
+	}
+	return ProfileStatus(s), errors.New("paypal: unknown recurring profile status " + s)
+}
+
+func (s ProfileStatus) String() string {
+	return string(s)
+}
+
+type BillingPeriod string
+
+const (
+	PeriodDay       BillingPeriod = "Day"
+	PeriodWeek      BillingPeriod = "Week"
+	PeriodSemiMonth BillingPeriod = "SemiMonth"
+	PeriodMonth     BillingPeriod = "Month"
+	PeriodYear      BillingPeriod = "Year"
+)
+
+func ParseBillingPeriod(s string) (BillingPeriod, error) {
+	period := BillingPeriod(s)
+	if _, ok := maxBillingFrequency[period]; !ok {
+		return period, errors.New("paypal: unknown billing period " + s)
+	}
+	return period, nil
+}
+
+func (p BillingPeriod) String() string {
+	return string(p)
+}
+
+type AutoBill string
+
+const (
+	NoAutoBill       AutoBill = "NoAutoBill"
+	AddToNextBilling AutoBill = "AddToNextBilling"
+)
+
+func ParseAutoBill(s string) (AutoBill, error) {
+	autoBill := AutoBill(s)
+	if autoBill != NoAutoBill && autoBill != AddToNextBilling {
+		return autoBill, errors.New("paypal: unknown auto bill setting " + s)
+	}
+	return autoBill, nil
+}
+
+func (a AutoBill) String() string {
+	return string(a)
+}
+
+type RecurringProfileRequest struct {
+	Token               string
+	SubscriberName      string
+	ProfileReference    string
+	Description         string
+	StartDate           time.Time
+	BillingPeriod       BillingPeriod
+	BillingFrequency    int
+	TotalBillingCycles  int
+	Amount              float64
+	CurrencyCode        string
+	ShippingAmount      float64
+	TaxAmount           float64
+	InitialAmount       float64
+	MaxFailedPayments   int
+	AutoBillOutstanding AutoBill
+
+	TrialBillingPeriod      BillingPeriod
+	TrialBillingFrequency   int
+	TrialTotalBillingCycles int
+	TrialAmount             float64
+}
+
+type RecurringProfileResponse struct {
+	*PayPalResponse
+	ProfileId     string
+	ProfileStatus ProfileStatus
+}
+
+var maxBillingFrequency = map[BillingPeriod]int{
+	PeriodDay:       365,
+	PeriodWeek:      52,
+	PeriodSemiMonth: 1,
+	PeriodMonth:     12,
+	PeriodYear:      1,
+}
+
+// ValidateSchedule checks a billing period and frequency against PayPal's
+// limit of at most one year per billing cycle. SemiMonth periods, billed on
+// the 1st and 15th, only allow a frequency of 1.
+func ValidateSchedule(period BillingPeriod, frequency int) error {
+	max, ok := maxBillingFrequency[period]
+	if !ok {
+		return errors.New("paypal: unknown billing period " + string(period))
+	}
+	if frequency <= 0 {
+		return fmt.Errorf("paypal: billing frequency must be positive, got %d", frequency)
+	}
+	if period == PeriodSemiMonth && frequency != 1 {
+		return fmt.Errorf("paypal: SemiMonth billing requires a frequency of 1, got %d", frequency)
+	}
+	if frequency > max {
+		return fmt.Errorf("paypal: a billing cycle of %d %s periods exceeds one year (at most %d)", frequency, period, max)
+	}
+	return nil
+}
+
+// Validate checks the regular and trial schedules and that the profile does
+// not start before today (UTC).
+func (r *RecurringProfileRequest) Validate() error {
+	if err := ValidateSchedule(r.BillingPeriod, r.BillingFrequency); err != nil {
+		return err
+	}
+	if len(r.TrialBillingPeriod) != 0 {
+		if err := ValidateSchedule(r.TrialBillingPeriod, r.TrialBillingFrequency); err != nil {
+			return fmt.Errorf("%v (trial period)", err)
+		}
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if r.StartDate.Before(today) {
+		return fmt.Errorf("paypal: recurring profile start date %s is in the past", r.StartDate.UTC().Format("2006-01-02"))
+	}
+	return nil
+}
+
+func (r *RecurringProfileRequest) values() (url.Values, error) {
+	if len(r.Token) == 0 {
+		return nil, errors.New("paypal: recurring profiles require an express checkout token")
+	}
+	if len(r.Description) == 0 {
+		return nil, errors.New("paypal: recurring profiles require the billing agreement description")
+	}
+	if r.StartDate.IsZero() || len(r.BillingPeriod) == 0 || r.BillingFrequency <= 0 {
+		return nil, errors.New("paypal: recurring profiles require a start date, billing period and frequency")
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "CreateRecurringPaymentsProfile")
+	values.Add("TOKEN", r.Token)
+	values.Add("DESC", r.Description)
+	values.Add("PROFILESTARTDATE", r.StartDate.UTC().Format(nvpDateLayout))
+	values.Add("BILLINGPERIOD", string(r.BillingPeriod))
+	values.Add("BILLINGFREQUENCY", fmt.Sprintf("%d", r.BillingFrequency))
+	values.Add("AMT", fmt.Sprintf("%.2f", r.Amount))
+	values.Add("CURRENCYCODE", r.CurrencyCode)
+	if len(r.SubscriberName) != 0 {
+		values.Add("SUBSCRIBERNAME", r.SubscriberName)
+	}
+	if len(r.ProfileReference) != 0 {
+		values.Add("PROFILEREFERENCE", r.ProfileReference)
+	}
+	if r.TotalBillingCycles != 0 {
+		values.Add("TOTALBILLINGCYCLES", fmt.Sprintf("%d", r.TotalBillingCycles))
+	}
+	if r.ShippingAmount != 0 {
+		values.Add("SHIPPINGAMT", fmt.Sprintf("%.2f", r.ShippingAmount))
+	}
+	if r.TaxAmount != 0 {
+		values.Add("TAXAMT", fmt.Sprintf("%.2f", r.TaxAmount))
+	}
+	if r.InitialAmount != 0 {
+		values.Add("INITAMT", fmt.Sprintf("%.2f", r.InitialAmount))
+	}
+	if r.MaxFailedPayments != 0 {
+		values.Add("MAXFAILEDPAYMENTS", fmt.Sprintf("%d", r.MaxFailedPayments))
+	}
+	if len(r.AutoBillOutstanding) != 0 {
+		values.Add("AUTOBILLOUTAMT", string(r.AutoBillOutstanding))
+	}
+	if len(r.TrialBillingPeriod) != 0 {
+		if r.TrialBillingFrequency <= 0 || r.TrialTotalBillingCycles <= 0 {
+			return nil, errors.New("paypal: a trial period requires a frequency and a number of billing cycles")
+		}
+		values.Add("TRIALBILLINGPERIOD", string(r.TrialBillingPeriod))
+		values.Add("TRIALBILLINGFREQUENCY", fmt.Sprintf("%d", r.TrialBillingFrequency))
+		values.Add("TRIALTOTALBILLINGCYCLES", fmt.Sprintf("%d", r.TrialTotalBillingCycles))
+		values.Add("TRIALAMT", fmt.Sprintf("%.2f", r.TrialAmount))
+	}
+
+	return values, nil
+}
+
+// RecurringProfileBuilder assembles a RecurringProfileRequest step by step and
+// reports combinations PayPal would reject, such as a trial without a regular
+// billing period, from Build.
+type RecurringProfileBuilder struct {
+	request RecurringProfileRequest
+	trial   bool
+}
+
+func NewRecurringProfile(token, description string, startDate time.Time) *RecurringProfileBuilder {
+	return &RecurringProfileBuilder{request: RecurringProfileRequest{Token: token, Description: description, StartDate: startDate}}
+}
+
+// Regular sets the regular billing period. A totalCycles of zero bills until
+// the profile is cancelled.
+func (b *RecurringProfileBuilder) Regular(period BillingPeriod, frequency, totalCycles int, amount float64, currencyCode string) *RecurringProfileBuilder {
+	b.request.BillingPeriod = period
+	b.request.BillingFrequency = frequency
+	b.request.TotalBillingCycles = totalCycles
+	b.request.Amount = amount
+	b.request.CurrencyCode = currencyCode
+	return b
+}
+
+// Trial sets a trial period billed before the regular one. A zero amount makes
+// the trial free.
+func (b *RecurringProfileBuilder) Trial(period BillingPeriod, frequency, totalCycles int, amount float64) *RecurringProfileBuilder {
+	b.trial = true
+	b.request.TrialBillingPeriod = period
+	b.request.TrialBillingFrequency = frequency
+	b.request.TrialTotalBillingCycles = totalCycles
+	b.request.TrialAmount = amount
+	return b
+}
+
+func (b *RecurringProfileBuilder) Subscriber(name, reference string) *RecurringProfileBuilder {
+	b.request.SubscriberName = name
+	b.request.ProfileReference = reference
+	return b
+}
+
+func (b *RecurringProfileBuilder) Build() (RecurringProfileRequest, error) {
+	if len(b.request.BillingPeriod) == 0 || b.request.BillingFrequency <= 0 {
+		if b.trial {
+			return b.request, errors.New("paypal: a trial period requires a regular billing period")
+		}
+		return b.request, errors.New("paypal: recurring profiles require a regular billing period")
+	}
+	if b.trial {
+		if len(b.request.TrialBillingPeriod) == 0 || b.request.TrialBillingFrequency <= 0 {
+			return b.request, errors.New("paypal: a trial period requires a billing period and frequency")
+		}
+		if b.request.TrialTotalBillingCycles <= 0 {
+			return b.request, errors.New("paypal: a trial period must have a finite number of billing cycles")
+		}
+	}
+	return b.request, b.request.Validate()
+}
+
+// CreateRecurringPaymentsProfile creates a recurring profile from an express
+// checkout token set up with a RecurringPayments billing type. The Description
+// must match the billing agreement description given to SetExpressCheckout.
+func (pClient *PayPalClient) CreateRecurringPaymentsProfile(request RecurringProfileRequest) (*RecurringProfileResponse, error) {
+	return pClient.CreateRecurringPaymentsProfileCtx(context.Background(), request)
+}
+
+func (pClient *PayPalClient) CreateRecurringPaymentsProfileCtx(ctx context.Context, request RecurringProfileRequest) (*RecurringProfileResponse, error) {
+	values, err := request.values()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	status, _ := ParseProfileStatus(response.Values.Get("PROFILESTATUS"))
+	return &RecurringProfileResponse{
+		PayPalResponse: response,
+		ProfileId:      response.Values.Get("PROFILEID"),
+		ProfileStatus:  status,
+	}, err
+}
+
+type RecurringProfile struct {
+	ProfileId           string
+	Status              ProfileStatus
+	Description         string
+	SubscriberName      string
+	ProfileReference    string
+	ProfileStartDate    time.Time
+	BillingPeriod       BillingPeriod
+	BillingFrequency    int
+	TotalBillingCycles  int
+	CurrencyCode        string
+	Amount              Money
+	ShippingAmount      Money
+	TaxAmount           Money
+	AutoBillOutstanding AutoBill
+	MaxFailedPayments   int
+	FailedPaymentCount  int
+	NextBillingDate     time.Time
+	CyclesCompleted     int
+	CyclesRemaining     int
+	OutstandingBalance  Money
+	LastPaymentDate     time.Time
+	LastPaymentAmount   Money
+	ShipToAddress       Address
+}
+
+type RecurringProfileDetails struct {
+	*PayPalResponse
+	Profile RecurringProfile
+}
+
+func parseRecurringProfile(values url.Values) RecurringProfile {
+	count := func(key string) int {
+		n, _ := strconv.Atoi(values.Get(key))
+		return n
+	}
+	status, _ := ParseProfileStatus(values.Get("STATUS"))
+	period, _ := ParseBillingPeriod(values.Get("BILLINGPERIOD"))
+	autoBill, _ := ParseAutoBill(values.Get("AUTOBILLOUTAMT"))
+	currencyCode := values.Get("CURRENCYCODE")
+	return RecurringProfile{
+		ProfileId:           values.Get("PROFILEID"),
+		Status:              status,
+		Description:         values.Get("DESC"),
+		SubscriberName:      values.Get("SUBSCRIBERNAME"),
+		ProfileReference:    values.Get("PROFILEREFERENCE"),
+		ProfileStartDate:    parseTime(values, "PROFILESTARTDATE"),
+		BillingPeriod:       period,
+		BillingFrequency:    count("BILLINGFREQUENCY"),
+		TotalBillingCycles:  count("TOTALBILLINGCYCLES"),
+		CurrencyCode:        currencyCode,
+		Amount:              parseMoney(values, "AMT", currencyCode),
+		ShippingAmount:      parseMoney(values, "SHIPPINGAMT", currencyCode),
+		TaxAmount:           parseMoney(values, "TAXAMT", currencyCode),
+		AutoBillOutstanding: autoBill,
+		MaxFailedPayments:   count("MAXFAILEDPAYMENTS"),
+		FailedPaymentCount:  count("FAILEDPAYMENTCOUNT"),
+		NextBillingDate:     parseTime(values, "NEXTBILLINGDATE"),
+		CyclesCompleted:     count("NUMCYCLESCOMPLETED"),
+		CyclesRemaining:     count("NUMCYCLESREMAINING"),
+		OutstandingBalance:  parseMoney(values, "OUTSTANDINGBALANCE", currencyCode),
+		LastPaymentDate:     parseTime(values, "LASTPAYMENTDATE"),
+		LastPaymentAmount:   parseMoney(values, "LASTPAYMENTAMT", currencyCode),
+		ShipToAddress:       parseAddress(values, ""),
+	}
+}
+
+func (pClient *PayPalClient) GetRecurringPaymentsProfileDetails(profileId string) (*RecurringProfileDetails, error) {
+	return pClient.GetRecurringPaymentsProfileDetailsCtx(context.Background(), profileId)
+}
+
+func (pClient *PayPalClient) GetRecurringPaymentsProfileDetailsCtx(ctx context.Context, profileId string) (*RecurringProfileDetails, error) {
+	values := url.Values{}
+	values.Set("METHOD", "GetRecurringPaymentsProfileDetails")
+	values.Add("PROFILEID", profileId)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &RecurringProfileDetails{response, parseRecurringProfile(response.Values)}, err
+}
+
+type ProfileAction string
+
+const (
+	ProfileCancel     ProfileAction = "Cancel"
+	ProfileSuspend    ProfileAction = "Suspend"
+	ProfileReactivate ProfileAction = "Reactivate"
+)
+
+var profileActionSources = map[ProfileAction][]ProfileStatus{
+	ProfileCancel:     {ProfileActive, ProfileSuspended},
+	ProfileSuspend:    {ProfileActive},
+	ProfileReactivate: {ProfileSuspended},
+}
+
+// ValidFrom reports whether PayPal accepts the action on a profile whose
+// GetRecurringPaymentsProfileDetails status is status.
+func (a ProfileAction) ValidFrom(status ProfileStatus) error {
+	sources, ok := profileActionSources[a]
+	if !ok {
+		return errors.New("paypal: unknown recurring profile action " + string(a))
+	}
+	for _, source := range sources {
+		if source == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("paypal: cannot %s a recurring profile that is %s", a, status)
+}
+
+type ProfileStatusResponse struct {
+	*PayPalResponse
+	ProfileId string
+}
+
+func (pClient *PayPalClient) ManageRecurringPaymentsProfileStatus(profileId string, action ProfileAction, note string) (*ProfileStatusResponse, error) {
+	return pClient.ManageRecurringPaymentsProfileStatusCtx(context.Background(), profileId, action, note)
+}
+
+func (pClient *PayPalClient) ManageRecurringPaymentsProfileStatusCtx(ctx context.Context, profileId string, action ProfileAction, note string) (*ProfileStatusResponse, error) {
+	if _, ok := profileActionSources[action]; !ok {
+		return nil, errors.New("paypal: unknown recurring profile action " + string(action))
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "ManageRecurringPaymentsProfileStatus")
+	values.Add("PROFILEID", profileId)
+	values.Add("ACTION", string(action))
+	if len(note) != 0 {
+		values.Add("NOTE", note)
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &ProfileStatusResponse{response, response.Values.Get("PROFILEID")}, err
+}
+
+// ChangeRecurringProfileStatus applies action to a previously fetched profile,
+// rejecting transitions from its current status before calling PayPal and
+// updating the status once PayPal accepts the change.
+func (pClient *PayPalClient) ChangeRecurringProfileStatus(profile *RecurringProfile, action ProfileAction, note string) (*ProfileStatusResponse, error) {
+	return pClient.ChangeRecurringProfileStatusCtx(context.Background(), profile, action, note)
+}
+
+func (pClient *PayPalClient) ChangeRecurringProfileStatusCtx(ctx context.Context, profile *RecurringProfile, action ProfileAction, note string) (*ProfileStatusResponse, error) {
+	if err := action.ValidFrom(profile.Status); err != nil {
+		return nil, err
+	}
+
+	response, err := pClient.ManageRecurringPaymentsProfileStatusCtx(ctx, profile.ProfileId, action, note)
+	if err == nil {
+		switch action {
+		case ProfileCancel:
+			profile.Status = ProfileCancelled
+		case ProfileSuspend:
+			profile.Status = ProfileSuspended
+		case ProfileReactivate:
+			profile.Status = ProfileActive
+		}
+	}
+	return response, err
+}
+
+// BillOutstandingAmount bills the outstanding balance of a recurring profile.
+// An amount of zero bills the full balance. As with DoAuthorization, a
+// msgSubId makes the call safe to retry.
+func (pClient *PayPalClient) BillOutstandingAmount(profileId string, amount float64, note, msgSubId string) (*ProfileStatusResponse, error) {
+	return pClient.BillOutstandingAmountCtx(context.Background(), profileId, amount, note, msgSubId)
+}
+
+func (pClient *PayPalClient) BillOutstandingAmountCtx(ctx context.Context, profileId string, amount float64, note, msgSubId string) (*ProfileStatusResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "BillOutstandingAmount")
+	values.Add("PROFILEID", profileId)
+	if amount != 0 {
+		values.Add("AMT", fmt.Sprintf("%.2f", amount))
+	}
+	if len(note) != 0 {
+		values.Add("NOTE", note)
+	}
+	if len(msgSubId) != 0 {
+		values.Add("MSGSUBID", msgSubId)
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &ProfileStatusResponse{response, response.Values.Get("PROFILEID")}, err
+}
@@ -0,0 +1,56 @@
+package paypal
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseRecurringProfileAmounts(t *testing.T) {
+	profile := parseRecurringProfile(url.Values{
+		"PROFILEID":          {"I-ABCDEFGHIJKL"},
+		"STATUS":             {"Active"},
+		"CURRENCYCODE":       {"JPY"},
+		"AMT":                {"1500"},
+		"SHIPPINGAMT":        {"300"},
+		"TAXAMT":             {"0"},
+		"OUTSTANDINGBALANCE": {"1500"},
+		"LASTPAYMENTAMT":     {"1800"},
+	})
+	for name, test := range map[string]struct {
+		got  Money
+		want Money
+	}{
+		"Amount":             {profile.Amount, Money{1500, "JPY"}},
+		"ShippingAmount":     {profile.ShippingAmount, Money{300, "JPY"}},
+		"TaxAmount":          {profile.TaxAmount, Money{0, "JPY"}},
+		"OutstandingBalance": {profile.OutstandingBalance, Money{1500, "JPY"}},
+		"LastPaymentAmount":  {profile.LastPaymentAmount, Money{1800, "JPY"}},
+	} {
+		if test.got != test.want {
+			t.Errorf("%s = %+v, want %+v", name, test.got, test.want)
+		}
+	}
+}
+
+func TestProfileActionValidFrom(t *testing.T) {
+	statuses := []ProfileStatus{ProfileActive, ProfilePending, ProfileCancelled, ProfileSuspended, ProfileExpired}
+	allowed := map[ProfileAction][]ProfileStatus{
+		ProfileCancel:     {ProfileActive, ProfileSuspended},
+		ProfileSuspend:    {ProfileActive},
+		ProfileReactivate: {ProfileSuspended},
+	}
+	for action, sources := range allowed {
+		for _, status := range statuses {
+			want := false
+			for _, source := range sources {
+				want = want || source == status
+			}
+			if err := action.ValidFrom(status); (err == nil) != want {
+				t.Errorf("%s from %s: got %v, want allowed %t", action, status, err, want)
+			}
+		}
+	}
+	if err := ProfileAction("Delete").ValidFrom(ProfileActive); err == nil {
+		t.Error("unknown action accepted")
+	}
+}
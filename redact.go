@@ -0,0 +1,76 @@
+package paypal
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// Keys never to be logged: API credentials and card data.
+var redactedKeys = map[string]bool{
+	"USER":      true,
+	"PWD":       true,
+	"SIGNATURE": true,
+	"SUBJECT":   true,
+	"ACCT":      true,
+	"CVV2":      true,
+}
+
+const redacted = "REDACTED"
+
+func redactValues(values url.Values) url.Values {
+	safe := url.Values{}
+	for key, value := range values {
+		if redactedKeys[key] {
+			safe[key] = []string{redacted}
+		} else {
+			safe[key] = value
+		}
+	}
+	return safe
+}
+
+// String returns the response values in NVP form with credentials and card
+// data redacted, so responses can be logged as is.
+func (r *PayPalResponse) String() string {
+	return redactValues(r.Values).Encode()
+}
+
+// MarshalJSON encodes the response with its redacted values. Since the typed
+// responses embed PayPalResponse, they are encoded the same way; everything
+// they parse is in Values.
+func (r *PayPalResponse) MarshalJSON() ([]byte, error) {
+	safe := redactValues(r.Values)
+	values := map[string]string{}
+	for key := range safe {
+		values[key] = safe.Get(key)
+	}
+	return json.Marshal(struct {
+		Ack           Ack               `json:"ack"`
+		CorrelationId string            `json:"correlationId,omitempty"`
+		Timestamp     string            `json:"timestamp,omitempty"`
+		Version       string            `json:"version,omitempty"`
+		Build         string            `json:"build,omitempty"`
+		StatusCode    int               `json:"statusCode,omitempty"`
+		Warnings      []PayPalWarning   `json:"warnings,omitempty"`
+		Values        map[string]string `json:"values"`
+	}{r.Ack, r.CorrelationId, r.Timestamp, r.Version, r.Build, r.StatusCode, r.Warnings, values})
+}
+
+func (e *PayPalError) String() string {
+	return e.Error()
+}
+
+func (e *PayPalError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Message       string              `json:"message"`
+		Ack           Ack                 `json:"ack,omitempty"`
+		ErrorCode     string              `json:"errorCode,omitempty"`
+		ShortMessage  string              `json:"shortMessage,omitempty"`
+		LongMessage   string              `json:"longMessage,omitempty"`
+		SeverityCode  string              `json:"severityCode,omitempty"`
+		Errors        []PayPalErrorDetail `json:"errors,omitempty"`
+		CorrelationId string              `json:"correlationId,omitempty"`
+		Method        string              `json:"method,omitempty"`
+		Timestamp     string              `json:"timestamp,omitempty"`
+	}{e.Error(), e.Ack, e.ErrorCode, e.ShortMessage, e.LongMessage, e.SeverityCode, e.Errors, e.CorrelationId, e.Method, e.Timestamp})
+}
@@ -0,0 +1,80 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+type RefundResponse struct {
+	*PayPalResponse
+	RefundTransactionId string
+	RefundStatus        string
+	PendingReason       PendingReason
+	CurrencyCode        string
+	FeeRefundAmount     Money
+	GrossRefundAmount   Money
+	NetRefundAmount     Money
+	TotalRefundedAmount Money
+}
+
+func newRefundResponse(r *PayPalResponse) *RefundResponse {
+	return &RefundResponse{
+		PayPalResponse:      r,
+		RefundTransactionId: r.Values.Get("REFUNDTRANSACTIONID"),
+		RefundStatus:        r.Values.Get("REFUNDSTATUS"),
+		PendingReason:       PendingReason(r.Values.Get("PENDINGREASON")),
+		CurrencyCode:        r.Values.Get("CURRENCYCODE"),
+		FeeRefundAmount:     parseMoney(r.Values, "FEEREFUNDAMT", r.Values.Get("CURRENCYCODE")),
+		GrossRefundAmount:   parseMoney(r.Values, "GROSSREFUNDAMT", r.Values.Get("CURRENCYCODE")),
+		NetRefundAmount:     parseMoney(r.Values, "NETREFUNDAMT", r.Values.Get("CURRENCYCODE")),
+		TotalRefundedAmount: parseMoney(r.Values, "TOTALREFUNDEDAMOUNT", r.Values.Get("CURRENCYCODE")),
+	}
+}
+
+func (pClient *PayPalClient) RefundFull(transactionId, note string) (*RefundResponse, error) {
+	return pClient.RefundFullCtx(context.Background(), transactionId, note)
+}
+
+func (pClient *PayPalClient) RefundFullCtx(ctx context.Context, transactionId, note string) (*RefundResponse, error) {
+	return pClient.RefundTransactionCtx(ctx, transactionId, "Full", "", 0, note)
+}
+
+func (pClient *PayPalClient) RefundPartial(transactionId, currencyCode string, amount float64, note string) (*RefundResponse, error) {
+	return pClient.RefundPartialCtx(context.Background(), transactionId, currencyCode, amount, note)
+}
+
+func (pClient *PayPalClient) RefundPartialCtx(ctx context.Context, transactionId, currencyCode string, amount float64, note string) (*RefundResponse, error) {
+	return pClient.RefundTransactionCtx(ctx, transactionId, "Partial", currencyCode, amount, note)
+}
+
+// RefundTransaction issues a refund against a previous transaction. The amount
+// and currency are only sent for non-full refunds, as PayPal rejects AMT on a
+// full refund.
+func (pClient *PayPalClient) RefundTransaction(transactionId, refundType, currencyCode string, amount float64, note string) (*RefundResponse, error) {
+	return pClient.RefundTransactionCtx(context.Background(), transactionId, refundType, currencyCode, amount, note)
+}
+
+func (pClient *PayPalClient) RefundTransactionCtx(ctx context.Context, transactionId, refundType, currencyCode string, amount float64, note string) (*RefundResponse, error) {
+	values := url.Values{}
+	values.Set("METHOD", "RefundTransaction")
+	values.Add("TRANSACTIONID", transactionId)
+	values.Add("REFUNDTYPE", refundType)
+	if refundType != "Full" {
+		if amount <= 0 {
+			return nil, errors.New("paypal: a " + refundType + " refund requires a positive amount")
+		}
+		values.Add("AMT", fmt.Sprintf("%.2f", amount))
+		values.Add("CURRENCYCODE", currencyCode)
+	}
+	if len(note) != 0 {
+		values.Add("NOTE", note)
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return newRefundResponse(response), err
+}
@@ -0,0 +1,80 @@
+package paypal
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy makes PerformRequest retry failed calls with exponential
+// backoff: the n-th retry waits BaseDelay * 2^(n-1), capped at MaxDelay and
+// shortened by up to Jitter (0 to 1) of itself at random, but at least the
+// RetryAfter of retryable TransportErrors. PayPal errors are only retried for
+// RetryableCodes. Calls moving money should carry an invoice id or MSGSUBID
+// so a retry cannot charge twice.
+type RetryPolicy struct {
+	MaxAttempts    int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	Jitter         float64
+	RetryableCodes []ErrorCode
+	OnAttempt      func(RetryAttempt)
+}
+
+// RetryAttempt describes a finished attempt. Delay is the wait before the next
+// attempt, zero if there is none.
+type RetryAttempt struct {
+	Method  string
+	Attempt int
+	Elapsed time.Duration
+	Err     error
+	Delay   time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	BaseDelay:      500 * time.Millisecond,
+	MaxDelay:       10 * time.Second,
+	Jitter:         0.2,
+	RetryableCodes: []ErrorCode{CodeInternalError, CodeTemporaryFailure},
+}
+
+func (p *RetryPolicy) retryable(err error) (time.Duration, bool) {
+	var tError *TransportError
+	if errors.As(err, &tError) {
+		return tError.RetryAfter, tError.Retryable()
+	}
+	var pError *PayPalError
+	if errors.As(err, &pError) {
+		for _, code := range p.RetryableCodes {
+			if pError.HasCode(code) {
+				return 0, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// delay returns the wait before retrying after the given attempt, and false
+// when the call should not be retried.
+func (p *RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	if err == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	retryAfter, ok := p.retryable(err)
+	if !ok {
+		return 0, false
+	}
+
+	delay := p.BaseDelay << uint(attempt-1)
+	if p.MaxDelay != 0 && (delay > p.MaxDelay || delay < 0) {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	if delay < retryAfter {
+		delay = retryAfter
+	}
+	return delay, true
+}
@@ -0,0 +1,209 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const nvpDateLayout = "2006-01-02T15:04:05Z"
+
+type TransactionSearchFilter struct {
+	StartDate        time.Time
+	EndDate          time.Time
+	Email            string
+	Receiver         string
+	ReceiptId        string
+	TransactionId    string
+	InvoiceNumber    string
+	ProfileId        string
+	TransactionClass string
+	Status           string
+	CurrencyCode     string
+	Amount           float64
+}
+
+type TransactionSearchResult struct {
+	Timestamp     time.Time
+	Timezone      string
+	Type          string
+	Email         string
+	Name          string
+	TransactionId string
+	Status        string
+	CurrencyCode  string
+	GrossAmount   Money
+	FeeAmount     Money
+	NetAmount     Money
+}
+
+type TransactionSearchResponse struct {
+	*PayPalResponse
+	Results []TransactionSearchResult
+}
+
+func (f *TransactionSearchFilter) values() (url.Values, error) {
+	if f.StartDate.IsZero() {
+		return nil, errors.New("paypal: transaction search requires a start date")
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "TransactionSearch")
+	values.Add("STARTDATE", f.StartDate.UTC().Format(nvpDateLayout))
+	if !f.EndDate.IsZero() {
+		values.Add("ENDDATE", f.EndDate.UTC().Format(nvpDateLayout))
+	}
+
+	optional := map[string]string{
+		"EMAIL":            f.Email,
+		"RECEIVER":         f.Receiver,
+		"RECEIPTID":        f.ReceiptId,
+		"TRANSACTIONID":    f.TransactionId,
+		"INVNUM":           f.InvoiceNumber,
+		"PROFILEID":        f.ProfileId,
+		"TRANSACTIONCLASS": f.TransactionClass,
+		"STATUS":           f.Status,
+		"CURRENCYCODE":     f.CurrencyCode,
+	}
+	for key, value := range optional {
+		if len(value) != 0 {
+			values.Add(key, value)
+		}
+	}
+	if f.Amount != 0 {
+		values.Add("AMT", fmt.Sprintf("%.2f", f.Amount))
+	}
+
+	return values, nil
+}
+
+func parseTransactionSearchResults(values url.Values) (results []TransactionSearchResult) {
+	for i := 0; ; i++ {
+		key := func(name string) string {
+			return fmt.Sprintf("L_%s%d", name, i)
+		}
+		if _, ok := values[key("TRANSACTIONID")]; !ok {
+			return
+		}
+
+		results = append(results, TransactionSearchResult{
+			Timestamp:     parseTime(values, key("TIMESTAMP")),
+			Timezone:      values.Get(key("TIMEZONE")),
+			Type:          values.Get(key("TYPE")),
+			Email:         values.Get(key("EMAIL")),
+			Name:          values.Get(key("NAME")),
+			TransactionId: values.Get(key("TRANSACTIONID")),
+			Status:        values.Get(key("STATUS")),
+			CurrencyCode:  values.Get(key("CURRENCYCODE")),
+			GrossAmount:   parseMoney(values, key("AMT"), values.Get(key("CURRENCYCODE"))),
+			FeeAmount:     parseMoney(values, key("FEEAMT"), values.Get(key("CURRENCYCODE"))),
+			NetAmount:     parseMoney(values, key("NETAMT"), values.Get(key("CURRENCYCODE"))),
+		})
+	}
+}
+
+// TransactionSearch returns at most 100 transactions matching the filter, most
+// recent first. When more exist PayPal still returns the first 100 together
+// with warning 11002.
+func (pClient *PayPalClient) TransactionSearch(filter TransactionSearchFilter) (*TransactionSearchResponse, error) {
+	return pClient.TransactionSearchCtx(context.Background(), filter)
+}
+
+func (pClient *PayPalClient) TransactionSearchCtx(ctx context.Context, filter TransactionSearchFilter) (*TransactionSearchResponse, error) {
+	values, err := filter.values()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &TransactionSearchResponse{response, parseTransactionSearchResults(response.Values)}, err
+}
+
+// TransactionIterator streams every transaction matching a filter, working
+// around the 100 result cap of TransactionSearch by narrowing the end date to
+// the oldest transaction seen whenever PayPal reports truncated results.
+type TransactionIterator struct {
+	ctx     context.Context
+	client  *PayPalClient
+	filter  TransactionSearchFilter
+	page    []TransactionSearchResult
+	current TransactionSearchResult
+	seen    map[string]bool
+	more    bool
+	err     error
+}
+
+func (pClient *PayPalClient) SearchTransactions(filter TransactionSearchFilter) *TransactionIterator {
+	return pClient.SearchTransactionsCtx(context.Background(), filter)
+}
+
+// SearchTransactionsCtx returns an iterator whose searches use ctx.
+func (pClient *PayPalClient) SearchTransactionsCtx(ctx context.Context, filter TransactionSearchFilter) *TransactionIterator {
+	return &TransactionIterator{ctx: ctx, client: pClient, filter: filter, more: true}
+}
+
+func (it *TransactionIterator) Next() bool {
+	for len(it.page) == 0 {
+		if !it.more || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *TransactionIterator) Transaction() TransactionSearchResult {
+	return it.current
+}
+
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
+
+func (it *TransactionIterator) fetch() {
+	response, err := it.client.TransactionSearchCtx(it.ctx, it.filter)
+	if err != nil {
+		it.err = err
+		return
+	}
+	truncated := response.HasWarning(string(CodeSearchTruncated))
+
+	// Transactions sharing the boundary timestamp are returned again by the
+	// next search, so only keep the ones not already handed out.
+	boundary := map[string]bool{}
+	var oldest time.Time
+	for _, result := range response.Results {
+		if !it.seen[result.TransactionId] {
+			it.page = append(it.page, result)
+		}
+		if !result.Timestamp.Equal(oldest) {
+			oldest = result.Timestamp
+			boundary = map[string]bool{}
+		}
+		boundary[result.TransactionId] = true
+	}
+	it.seen = boundary
+
+	it.more = false
+	if !truncated || len(response.Results) == 0 {
+		return
+	}
+
+	if oldest.IsZero() {
+		it.err = errors.New("paypal: cannot continue transaction search without result timestamps")
+		return
+	}
+	if len(it.page) == 0 {
+		it.err = errors.New("paypal: more than 100 transactions share timestamp " + oldest.Format(nvpDateLayout))
+		return
+	}
+	it.filter.EndDate = oldest
+	it.more = true
+}
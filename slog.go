@@ -0,0 +1,70 @@
+package paypal
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// LogLevels are the levels calls are logged at with WithSlog, by outcome.
+type LogLevels struct {
+	Success slog.Level
+	Warning slog.Level
+	Failure slog.Level
+}
+
+var DefaultLogLevels = LogLevels{Success: slog.LevelInfo, Warning: slog.LevelWarn, Failure: slog.LevelError}
+
+// WithSlog logs every call attempt with its method, request parameters minus
+// credentials and card data, correlation id, ack and latency.
+func WithSlog(logger *slog.Logger) Option {
+	return func(pClient *PayPalClient) {
+		pClient.slogger = logger
+	}
+}
+
+func WithSlogLevels(levels LogLevels) Option {
+	return func(pClient *PayPalClient) {
+		pClient.logLevels = &levels
+	}
+}
+
+func (pClient *PayPalClient) slogCall(ctx context.Context, values url.Values, attempt int, elapsed time.Duration, response *PayPalResponse, err error) {
+	levels := DefaultLogLevels
+	if pClient.logLevels != nil {
+		levels = *pClient.logLevels
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if !redactedKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	params := make([]interface{}, len(keys))
+	for i, key := range keys {
+		params[i] = slog.String(key, values.Get(key))
+	}
+
+	level := levels.Success
+	attrs := []interface{}{
+		slog.String("method", values.Get("METHOD")),
+		slog.Int("attempt", attempt),
+		slog.Duration("latency", elapsed),
+		slog.Group("params", params...),
+	}
+	if response != nil {
+		attrs = append(attrs, slog.String("ack", string(response.Ack)), slog.String("correlation_id", response.CorrelationId))
+		if len(response.Warnings) != 0 {
+			level = levels.Warning
+		}
+	}
+	if err != nil {
+		level = levels.Failure
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	pClient.slogger.Log(ctx, level, "paypal call", attrs...)
+}
@@ -0,0 +1,86 @@
+package paypal
+
+type PaymentStatus string
+
+const (
+	PaymentNone               PaymentStatus = "None"
+	PaymentCanceledReversal   PaymentStatus = "Canceled-Reversal"
+	PaymentCompleted          PaymentStatus = "Completed"
+	PaymentCompletedFundsHeld PaymentStatus = "Completed-Funds-Held"
+	PaymentDenied             PaymentStatus = "Denied"
+	PaymentExpired            PaymentStatus = "Expired"
+	PaymentFailed             PaymentStatus = "Failed"
+	PaymentInProgress         PaymentStatus = "In-Progress"
+	PaymentPartiallyRefunded  PaymentStatus = "Partially-Refunded"
+	PaymentPending            PaymentStatus = "Pending"
+	PaymentProcessed          PaymentStatus = "Processed"
+	PaymentRefunded           PaymentStatus = "Refunded"
+	PaymentReversed           PaymentStatus = "Reversed"
+	PaymentVoided             PaymentStatus = "Voided"
+)
+
+func (s PaymentStatus) String() string {
+	return string(s)
+}
+
+func (s PaymentStatus) IsPending() bool {
+	return s == PaymentPending || s == PaymentInProgress
+}
+
+// IsCompleted reports whether the funds were captured, including payments
+// whose funds PayPal holds back for a while.
+func (s PaymentStatus) IsCompleted() bool {
+	return s == PaymentCompleted || s == PaymentCompletedFundsHeld || s == PaymentProcessed
+}
+
+// IsReversed reports whether some or all of the funds went back to the buyer,
+// be it through a refund or a reversal such as a chargeback.
+func (s PaymentStatus) IsReversed() bool {
+	return s == PaymentReversed || s == PaymentRefunded || s == PaymentPartiallyRefunded
+}
+
+func (s PaymentStatus) IsFailed() bool {
+	return s == PaymentDenied || s == PaymentExpired || s == PaymentFailed || s == PaymentVoided
+}
+
+type PendingReason string
+
+const (
+	PendingNone             PendingReason = "none"
+	PendingAddress          PendingReason = "address"
+	PendingAuthorization    PendingReason = "authorization"
+	PendingECheck           PendingReason = "echeck"
+	PendingIntl             PendingReason = "intl"
+	PendingMultiCurrency    PendingReason = "multi-currency"
+	PendingOrder            PendingReason = "order"
+	PendingPaymentReview    PendingReason = "paymentreview"
+	PendingRegulatoryReview PendingReason = "regulatoryreview"
+	PendingUnilateral       PendingReason = "unilateral"
+	PendingVerify           PendingReason = "verify"
+	PendingOther            PendingReason = "other"
+)
+
+func (r PendingReason) String() string {
+	return string(r)
+}
+
+// IsReview reports whether PayPal holds the payment for a risk or regulatory
+// review, in which case the order should not be shipped yet.
+func (r PendingReason) IsReview() bool {
+	return r == PendingPaymentReview || r == PendingRegulatoryReview
+}
+
+type ReasonCode string
+
+const (
+	ReasonNone           ReasonCode = "none"
+	ReasonChargeback     ReasonCode = "chargeback"
+	ReasonGuarantee      ReasonCode = "guarantee"
+	ReasonBuyerComplaint ReasonCode = "buyer-complaint"
+	ReasonRefund         ReasonCode = "refund"
+	ReasonOther          ReasonCode = "other"
+)
+
+func (c ReasonCode) String() string {
+	return string(c)
+}
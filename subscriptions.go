@@ -0,0 +1,292 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSubscriptionNotFound = errors.New("paypal: subscription not found")
+
+type SubscriptionPlan struct {
+	Description        string
+	BillingPeriod      BillingPeriod
+	BillingFrequency   int
+	TotalBillingCycles int
+	Amount             float64
+	CurrencyCode       string
+	MaxFailedPayments  int
+}
+
+type Subscription struct {
+	Id                 string
+	Token              string
+	ProfileId          string
+	Status             ProfileStatus
+	Plan               SubscriptionPlan
+	NextBillingDate    time.Time
+	LastPaymentDate    time.Time
+	LastPaymentAmount  Money
+	FailedPaymentCount int
+	UpdatedAt          time.Time
+	// LastIPNId is the ipn_track_id, or txn_id, of the last notification
+	// applied, so redeliveries are not counted twice.
+	LastIPNId string
+}
+
+// SubscriptionStore persists subscriptions for Subscriptions. Lookups of
+// unknown subscriptions must return ErrSubscriptionNotFound.
+type SubscriptionStore interface {
+	Get(id string) (*Subscription, error)
+	GetByProfileId(profileId string) (*Subscription, error)
+	Save(subscription *Subscription) error
+}
+
+// Subscriptions runs the recurring billing lifecycle on top of the low level
+// calls: SetExpressCheckout with a RecurringPayments agreement, profile
+// creation once the buyer returns, status changes and the recurring_payment
+// IPN messages that keep the stored state current.
+type Subscriptions struct {
+	client    *PayPalClient
+	store     SubscriptionStore
+	returnUrl string
+	cancelUrl string
+
+	// OnEvent, when set, is called after an IPN message updated a subscription.
+	OnEvent func(subscription *Subscription, txnType string, values url.Values)
+}
+
+func NewSubscriptions(client *PayPalClient, store SubscriptionStore, returnUrl, cancelUrl string) *Subscriptions {
+	return &Subscriptions{client: client, store: store, returnUrl: returnUrl, cancelUrl: cancelUrl}
+}
+
+// Begin stores a pending subscription and returns the PayPal URL the buyer must
+// be redirected to in order to approve the billing agreement.
+func (s *Subscriptions) Begin(id string, plan SubscriptionPlan) (string, error) {
+	return s.BeginCtx(context.Background(), id, plan)
+}
+
+func (s *Subscriptions) BeginCtx(ctx context.Context, id string, plan SubscriptionPlan) (string, error) {
+	if err := ValidateSchedule(plan.BillingPeriod, plan.BillingFrequency); err != nil {
+		return "", err
+	}
+
+	agreement := BillingAgreement{Type: RecurringPayments, Description: plan.Description}
+	response, err := s.client.SetExpressCheckoutDigitalGoodsCtx(ctx, 0, plan.CurrencyCode, s.returnUrl, s.cancelUrl, id, nil, agreement)
+	if err != nil {
+		return "", err
+	}
+
+	subscription := &Subscription{Id: id, Token: response.Values.Get("TOKEN"), Status: ProfilePending, Plan: plan, UpdatedAt: time.Now()}
+	if err := s.store.Save(subscription); err != nil {
+		return "", err
+	}
+	return response.CheckoutUrlWith(CheckoutUrlOptions{})
+}
+
+// Complete creates the recurring profile once the buyer has returned from
+// PayPal with token.
+func (s *Subscriptions) Complete(id, token string, startDate time.Time) (*Subscription, error) {
+	return s.CompleteCtx(context.Background(), id, token, startDate)
+}
+
+func (s *Subscriptions) CompleteCtx(ctx context.Context, id, token string, startDate time.Time) (*Subscription, error) {
+	subscription, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Token != token {
+		return nil, errors.New("paypal: token does not belong to subscription " + id)
+	}
+	if len(subscription.ProfileId) != 0 {
+		return subscription, nil
+	}
+
+	plan := subscription.Plan
+	response, err := s.client.CreateRecurringPaymentsProfileCtx(ctx, RecurringProfileRequest{
+		Token:              token,
+		Description:        plan.Description,
+		ProfileReference:   id,
+		StartDate:          startDate,
+		BillingPeriod:      plan.BillingPeriod,
+		BillingFrequency:   plan.BillingFrequency,
+		TotalBillingCycles: plan.TotalBillingCycles,
+		Amount:             plan.Amount,
+		CurrencyCode:       plan.CurrencyCode,
+		MaxFailedPayments:  plan.MaxFailedPayments,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.ProfileId = response.ProfileId
+	subscription.Status = response.ProfileStatus
+	subscription.UpdatedAt = time.Now()
+	return subscription, s.store.Save(subscription)
+}
+
+func (s *Subscriptions) Cancel(id, note string) (*Subscription, error) {
+	return s.CancelCtx(context.Background(), id, note)
+}
+
+func (s *Subscriptions) CancelCtx(ctx context.Context, id, note string) (*Subscription, error) {
+	return s.changeStatus(ctx, id, ProfileCancel, note)
+}
+
+func (s *Subscriptions) Suspend(id, note string) (*Subscription, error) {
+	return s.SuspendCtx(context.Background(), id, note)
+}
+
+func (s *Subscriptions) SuspendCtx(ctx context.Context, id, note string) (*Subscription, error) {
+	return s.changeStatus(ctx, id, ProfileSuspend, note)
+}
+
+func (s *Subscriptions) Reactivate(id, note string) (*Subscription, error) {
+	return s.ReactivateCtx(context.Background(), id, note)
+}
+
+func (s *Subscriptions) ReactivateCtx(ctx context.Context, id, note string) (*Subscription, error) {
+	return s.changeStatus(ctx, id, ProfileReactivate, note)
+}
+
+func (s *Subscriptions) changeStatus(ctx context.Context, id string, action ProfileAction, note string) (*Subscription, error) {
+	subscription, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	profile := &RecurringProfile{ProfileId: subscription.ProfileId, Status: subscription.Status}
+	if _, err := s.client.ChangeRecurringProfileStatusCtx(ctx, profile, action, note); err != nil {
+		return nil, err
+	}
+
+	subscription.Status = profile.Status
+	subscription.UpdatedAt = time.Now()
+	return subscription, s.store.Save(subscription)
+}
+
+// Refresh reloads a subscription's state from its recurring profile.
+func (s *Subscriptions) Refresh(id string) (*Subscription, error) {
+	return s.RefreshCtx(context.Background(), id)
+}
+
+func (s *Subscriptions) RefreshCtx(ctx context.Context, id string) (*Subscription, error) {
+	subscription, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	details, err := s.client.GetRecurringPaymentsProfileDetailsCtx(ctx, subscription.ProfileId)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := details.Profile
+	subscription.Status = profile.Status
+	subscription.NextBillingDate = profile.NextBillingDate
+	subscription.LastPaymentDate = profile.LastPaymentDate
+	subscription.LastPaymentAmount = profile.LastPaymentAmount
+	subscription.FailedPaymentCount = profile.FailedPaymentCount
+	subscription.UpdatedAt = time.Now()
+	return subscription, s.store.Save(subscription)
+}
+
+// HandleIPN applies a recurring_payment* notification to the subscription
+// owning its profile. Notifications of other types or for unknown profiles
+// are ignored, as are redeliveries of the last notification applied, so
+// PayPal does not keep resending them.
+func (s *Subscriptions) HandleIPN(values url.Values) error {
+	txnType := values.Get("txn_type")
+	if !strings.HasPrefix(txnType, "recurring_payment") {
+		return nil
+	}
+
+	subscription, err := s.store.GetByProfileId(values.Get("recurring_payment_id"))
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ipnId := values.Get("ipn_track_id")
+	if len(ipnId) == 0 {
+		ipnId = values.Get("txn_id")
+	}
+	if len(ipnId) != 0 && ipnId == subscription.LastIPNId {
+		return nil
+	}
+	subscription.LastIPNId = ipnId
+
+	if status := values.Get("profile_status"); len(status) != 0 {
+		subscription.Status, _ = ParseProfileStatus(status)
+	}
+	if next, err := ParseIPNDate(values.Get("next_payment_date")); err == nil {
+		subscription.NextBillingDate = next
+	}
+	switch txnType {
+	case "recurring_payment":
+		if values.Get("payment_status") == "Completed" {
+			subscription.LastPaymentDate, _ = ParseIPNDate(values.Get("payment_date"))
+			subscription.LastPaymentAmount, _ = ParseMoney(values.Get("mc_gross"), values.Get("mc_currency"))
+			subscription.FailedPaymentCount = 0
+		}
+	case "recurring_payment_failed", "recurring_payment_skipped":
+		subscription.FailedPaymentCount++
+	}
+	subscription.UpdatedAt = time.Now()
+
+	if err := s.store.Save(subscription); err != nil {
+		return err
+	}
+	if s.OnEvent != nil {
+		s.OnEvent(subscription, txnType, values)
+	}
+	return nil
+}
+
+// IPNHandler returns an http.Handler for the IPN notification URL that verifies
+// each message and feeds it to HandleIPN.
+func (s *Subscriptions) IPNHandler() http.Handler {
+	return s.client.IPNHandler(s.HandleIPN)
+}
+
+// MemorySubscriptionStore is an in-memory SubscriptionStore, suitable for tests
+// and single process deployments.
+type MemorySubscriptionStore struct {
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+}
+
+func NewMemorySubscriptionStore() *MemorySubscriptionStore {
+	return &MemorySubscriptionStore{subscriptions: map[string]Subscription{}}
+}
+
+func (m *MemorySubscriptionStore) Get(id string) (*Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscription, ok := m.subscriptions[id]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	return &subscription, nil
+}
+
+func (m *MemorySubscriptionStore) GetByProfileId(profileId string) (*Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, subscription := range m.subscriptions {
+		if len(profileId) != 0 && subscription.ProfileId == profileId {
+			return &subscription, nil
+		}
+	}
+	return nil, ErrSubscriptionNotFound
+}
+
+func (m *MemorySubscriptionStore) Save(subscription *Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscriptions[subscription.Id] = *subscription
+	return nil
+}
@@ -0,0 +1,74 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestSubscriptionsCancelCtxCanceled(t *testing.T) {
+	pClient, lastRequest := newTestClient(t, "ACK=Success&PROFILEID=I-ABCDEFGHIJKL")
+	store := NewMemorySubscriptionStore()
+	store.Save(&Subscription{Id: "sub-1", ProfileId: "I-ABCDEFGHIJKL", Status: ProfileActive})
+	subscriptions := NewSubscriptions(pClient, store, "https://shop.example.com/return", "https://shop.example.com/cancel")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := subscriptions.CancelCtx(ctx, "sub-1", "moving away"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+	if lastRequest() != nil {
+		t.Error("request sent despite the canceled context")
+	}
+	subscription, err := store.Get("sub-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.Status != ProfileActive {
+		t.Errorf("status changed to %s", subscription.Status)
+	}
+}
+
+func TestSubscriptionsHandleIPN(t *testing.T) {
+	pClient, _ := newTestClient(t, "ACK=Success")
+	store := NewMemorySubscriptionStore()
+	store.Save(&Subscription{Id: "sub-1", ProfileId: "I-ABCDEFGHIJKL", Status: ProfileActive})
+	subscriptions := NewSubscriptions(pClient, store, "https://shop.example.com/return", "https://shop.example.com/cancel")
+
+	unknown := url.Values{"txn_type": {"recurring_payment_failed"}, "recurring_payment_id": {"I-UNKNOWN"}, "ipn_track_id": {"a1b2c3"}}
+	if err := subscriptions.HandleIPN(unknown); err != nil {
+		t.Errorf("unknown profile: got %v, want nil", err)
+	}
+
+	failed := url.Values{
+		"txn_type":             {"recurring_payment_failed"},
+		"recurring_payment_id": {"I-ABCDEFGHIJKL"},
+		"profile_status":       {"Active"},
+		"ipn_track_id":         {"d4e5f6"},
+	}
+	for i := 0; i < 2; i++ {
+		if err := subscriptions.HandleIPN(failed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	subscription, err := store.Get("sub-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.FailedPaymentCount != 1 {
+		t.Errorf("FailedPaymentCount = %d after a redelivery, want 1", subscription.FailedPaymentCount)
+	}
+
+	skipped := url.Values{
+		"txn_type":             {"recurring_payment_skipped"},
+		"recurring_payment_id": {"I-ABCDEFGHIJKL"},
+		"ipn_track_id":         {"g7h8i9"},
+	}
+	if err := subscriptions.HandleIPN(skipped); err != nil {
+		t.Fatal(err)
+	}
+	if subscription, _ = store.Get("sub-1"); subscription.FailedPaymentCount != 2 {
+		t.Errorf("FailedPaymentCount = %d, want 2", subscription.FailedPaymentCount)
+	}
+}
@@ -0,0 +1,78 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+type TransactionDetails struct {
+	*PayPalResponse
+	Payer         Payer
+	ShipToAddress Address
+	PaymentInfo   PaymentInfo
+	Items         []ItemDetails
+	InvoiceNumber string
+	Custom        string
+	Note          string
+}
+
+// GetTransactionDetails looks up a single transaction. For refunds and
+// reversals PaymentInfo.ParentTransactionId links back to the original payment.
+func (pClient *PayPalClient) GetTransactionDetails(transactionId string) (*TransactionDetails, error) {
+	return pClient.GetTransactionDetailsCtx(context.Background(), transactionId)
+}
+
+func (pClient *PayPalClient) GetTransactionDetailsCtx(ctx context.Context, transactionId string) (*TransactionDetails, error) {
+	values := url.Values{}
+	values.Set("METHOD", "GetTransactionDetails")
+	values.Add("TRANSACTIONID", transactionId)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &TransactionDetails{
+		PayPalResponse: response,
+		Payer:          parsePayer(response.Values),
+		ShipToAddress:  parseAddress(response.Values, ""),
+		PaymentInfo:    parsePaymentInfo(response.Values, "", response.settleCurrency),
+		Items:          parseLineItems(response.Values, "", response.Values.Get("CURRENCYCODE")),
+		InvoiceNumber:  response.Values.Get("INVNUM"),
+		Custom:         response.Values.Get("CUSTOM"),
+		Note:           response.Values.Get("NOTE"),
+	}, err
+}
+
+type PendingTransactionStatusResponse struct {
+	*PayPalResponse
+	TransactionId string
+	Status        string
+}
+
+// ManagePendingTransactionStatus accepts or denies a payment held for review,
+// such as one flagged by Fraud Management Filters. Action is "Accept" or "Deny".
+func (pClient *PayPalClient) ManagePendingTransactionStatus(transactionId, action string) (*PendingTransactionStatusResponse, error) {
+	return pClient.ManagePendingTransactionStatusCtx(context.Background(), transactionId, action)
+}
+
+func (pClient *PayPalClient) ManagePendingTransactionStatusCtx(ctx context.Context, transactionId, action string) (*PendingTransactionStatusResponse, error) {
+	if action != "Accept" && action != "Deny" {
+		return nil, errors.New("paypal: invalid pending transaction action " + action)
+	}
+
+	values := url.Values{}
+	values.Set("METHOD", "ManagePendingTransactionStatus")
+	values.Add("TRANSACTIONID", transactionId)
+	values.Add("ACTION", action)
+
+	response, err := pClient.PerformRequestCtx(ctx, values)
+	if response == nil {
+		return nil, err
+	}
+	return &PendingTransactionStatusResponse{
+		PayPalResponse: response,
+		TransactionId:  response.Values.Get("TRANSACTIONID"),
+		Status:         response.Values.Get("STATUS"),
+	}, err
+}
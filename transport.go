@@ -0,0 +1,224 @@
+package paypal
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Defaults of the client's own transport, see NewClient.
+const (
+	defaultTimeout               = time.Minute
+	defaultConnectTimeout        = 10 * time.Second
+	defaultKeepAlive             = 30 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultResponseHeaderTimeout = 30 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultMaxIdleConnsPerHost   = 10
+)
+
+func newDefaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: defaultConnectTimeout, KeepAlive: defaultKeepAlive}).DialContext
+	transport.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = defaultResponseHeaderTimeout
+	transport.IdleConnTimeout = defaultIdleConnTimeout
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	return transport
+}
+
+// transportOptions are applied to a copy of the HTTP client's transport
+// when any of them is set.
+type transportOptions struct {
+	proxy     func(*http.Request) (*url.URL, error)
+	tlsConfig *tls.Config
+	dialer    *net.Dialer
+	minTLS    uint16
+	pins      map[string]bool
+
+	connectTimeout        time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	// certificate is set by CertificateCredentials.
+	certificate *tls.Certificate
+}
+
+// ErrCertificatePinMismatch is wrapped by the TransportError of calls to a
+// server whose certificate chain has none of the public keys given to
+// WithPinnedPublicKeys.
+var ErrCertificatePinMismatch = errors.New("paypal: certificate chain matches no pinned public key")
+
+// WithProxy routes all calls through the given HTTP proxy. By default the
+// transport's own setting applies, the environment's HTTPS_PROXY for the
+// default transport. Like WithTLSConfig and WithDialer, it has no effect when
+// the HTTP client's Transport is something else than an *http.Transport.
+func WithProxy(proxyUrl *url.URL) Option {
+	return func(pClient *PayPalClient) {
+		pClient.transport.proxy = http.ProxyURL(proxyUrl)
+	}
+}
+
+// WithTLSConfig replaces the TLS configuration of the transport, for instance
+// to trust a custom CA bundle through RootCAs.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(pClient *PayPalClient) {
+		pClient.transport.tlsConfig = config
+	}
+}
+
+// WithDialer opens connections with dialer, to set connect timeouts, keep
+// alives or the local address.
+func WithDialer(dialer *net.Dialer) Option {
+	return func(pClient *PayPalClient) {
+		pClient.transport.dialer = dialer
+	}
+}
+
+// WithMinTLSVersion refuses connections negotiating a TLS version older than
+// version, e.g. tls.VersionTLS12. The handshake then fails with a
+// TransportError instead of falling back.
+func WithMinTLSVersion(version uint16) Option {
+	return func(pClient *PayPalClient) {
+		pClient.transport.minTLS = version
+	}
+}
+
+// WithPinnedPublicKeys only accepts servers with one of the given public keys
+// in their verified certificate chain, on top of the usual verification. Pins
+// are base64 SHA-256 digests of the DER encoded SubjectPublicKeyInfo, as
+// printed by
+//
+//	openssl x509 -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+//
+// Pin an intermediate or root key as well as the leaf, PayPal rotates its
+// certificates.
+func WithPinnedPublicKeys(pins ...string) Option {
+	return func(pClient *PayPalClient) {
+		if pClient.transport.pins == nil {
+			pClient.transport.pins = map[string]bool{}
+		}
+		for _, pin := range pins {
+			pClient.transport.pins[pin] = true
+		}
+	}
+}
+
+// WithConnectTimeout bounds establishing a TCP connection, 10 seconds by
+// default. It overrides the timeout of the WithDialer dialer.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(pClient *PayPalClient) {
+		pClient.transport.connectTimeout = timeout
+	}
+}
+
+// WithTLSHandshakeTimeout bounds the TLS handshake, 10 seconds by default.
+func WithTLSHandshakeTimeout(timeout time.Duration) Option {
+	return func(pClient *PayPalClient) {
+		pClient.transport.tlsHandshakeTimeout = timeout
+	}
+}
+
+// WithResponseHeaderTimeout bounds the wait for PayPal's response once the
+// request is sent, 30 seconds by default. Use WithTimeout for the whole call.
+func WithResponseHeaderTimeout(timeout time.Duration) Option {
+	return func(pClient *PayPalClient) {
+		pClient.transport.responseHeaderTimeout = timeout
+	}
+}
+
+func (o transportOptions) isSet() bool {
+	return o.proxy != nil || o.tlsConfig != nil || o.dialer != nil || o.minTLS != 0 || len(o.pins) != 0 || o.certificate != nil ||
+		o.connectTimeout != 0 || o.tlsHandshakeTimeout != 0 || o.responseHeaderTimeout != 0
+}
+
+func publicKeyPin(certificate *x509.Certificate) string {
+	digest := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// verifyPins returns a tls.Config.VerifyConnection func checking the pins
+// after next, if any.
+func verifyPins(pins map[string]bool, next func(tls.ConnectionState) error) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if next != nil {
+			if err := next(state); err != nil {
+				return err
+			}
+		}
+		for _, chain := range state.VerifiedChains {
+			for _, certificate := range chain {
+				if pins[publicKeyPin(certificate)] {
+					return nil
+				}
+			}
+		}
+		if len(state.ServerName) == 0 {
+			return ErrCertificatePinMismatch
+		}
+		return fmt.Errorf("%w for %s", ErrCertificatePinMismatch, state.ServerName)
+	}
+}
+
+// configureTransport copies the client's *http.Transport, or the default one,
+// and applies the options to the copy. Custom RoundTrippers are left alone.
+func (pClient *PayPalClient) configureTransport() {
+	if !pClient.transport.isSet() {
+		return
+	}
+	var transport *http.Transport
+	switch base := pClient.client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = base.Clone()
+	default:
+		return
+	}
+	if pClient.transport.proxy != nil {
+		transport.Proxy = pClient.transport.proxy
+	}
+	if pClient.transport.tlsConfig != nil {
+		transport.TLSClientConfig = pClient.transport.tlsConfig.Clone()
+	} else if transport.TLSClientConfig != nil {
+		transport.TLSClientConfig = transport.TLSClientConfig.Clone()
+	} else {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	if pClient.transport.minTLS > transport.TLSClientConfig.MinVersion {
+		transport.TLSClientConfig.MinVersion = pClient.transport.minTLS
+	}
+	if pClient.transport.certificate != nil {
+		transport.TLSClientConfig.Certificates = append(transport.TLSClientConfig.Certificates, *pClient.transport.certificate)
+	}
+	if len(pClient.transport.pins) != 0 {
+		transport.TLSClientConfig.VerifyConnection = verifyPins(pClient.transport.pins, transport.TLSClientConfig.VerifyConnection)
+	}
+	dialer := pClient.transport.dialer
+	if pClient.transport.connectTimeout != 0 {
+		timed := net.Dialer{KeepAlive: defaultKeepAlive}
+		if dialer != nil {
+			timed = *dialer
+		}
+		timed.Timeout = pClient.transport.connectTimeout
+		dialer = &timed
+	}
+	if dialer != nil {
+		transport.DialContext = dialer.DialContext
+	}
+	if pClient.transport.tlsHandshakeTimeout != 0 {
+		transport.TLSHandshakeTimeout = pClient.transport.tlsHandshakeTimeout
+	}
+	if pClient.transport.responseHeaderTimeout != 0 {
+		transport.ResponseHeaderTimeout = pClient.transport.responseHeaderTimeout
+	}
+	client := *pClient.client
+	client.Transport = transport
+	pClient.client = &client
+}
@@ -0,0 +1,374 @@
+package paypal
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Payer struct {
+	PayerId     string
+	Email       string
+	FirstName   string
+	LastName    string
+	Business    string
+	CountryCode string
+	PayerStatus string
+}
+
+// Verified reports whether the payer has verified their PayPal account.
+func (p Payer) Verified() bool {
+	return strings.EqualFold(p.PayerStatus, "verified")
+}
+
+type Address struct {
+	Name        string
+	Street      string
+	Street2     string
+	City        string
+	State       string
+	Zip         string
+	CountryCode string
+	Phone       string
+	Status      AddressStatus
+}
+
+type AddressStatus string
+
+const (
+	AddressNone        AddressStatus = "None"
+	AddressConfirmed   AddressStatus = "Confirmed"
+	AddressUnconfirmed AddressStatus = "Unconfirmed"
+)
+
+func (s AddressStatus) String() string {
+	return string(s)
+}
+
+// Confirmed reports whether PayPal confirmed the address belongs to the buyer,
+// a requirement for seller protection on physical goods.
+func (a Address) Confirmed() bool {
+	return strings.EqualFold(string(a.Status), string(AddressConfirmed))
+}
+
+// Validate checks the fields PayPal requires of an address sent to it, such
+// as one overriding the buyer's shipping address.
+func (a Address) Validate() error {
+	switch {
+	case len(a.Name) == 0:
+		return errors.New("paypal: address requires a name")
+	case len(a.Street) == 0:
+		return errors.New("paypal: address requires a street")
+	case len(a.City) == 0:
+		return errors.New("paypal: address requires a city")
+	case len(normalizeCountryCode(a.CountryCode)) != 2:
+		return errors.New("paypal: address requires a two letter country code")
+	}
+	return nil
+}
+
+// normalizeCountryCode upper-cases ISO 3166 country codes, mapping the common
+// UK to the GB PayPal expects.
+func normalizeCountryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "UK" {
+		return "GB"
+	}
+	return code
+}
+
+// PaymentInfo describes a single payment. In DoExpressCheckoutPayment responses
+// with several payment requests, each leg has its own Ack, and a leg that
+// failed carries its ErrorCode and messages instead of a TransactionId.
+type PaymentInfo struct {
+	Ack                   Ack
+	PaymentRequestId      string
+	SellerAccountId       string
+	TransactionId         string
+	ParentTransactionId   string
+	ReceiptId             string
+	TransactionType       string
+	PaymentType           string
+	OrderTime             time.Time
+	PaymentStatus         PaymentStatus
+	PendingReason         PendingReason
+	ReasonCode            ReasonCode
+	ProtectionEligibility ProtectionEligibility
+	FraudFilters          FraudFilters
+	CurrencyCode          string
+	Amount                Money
+	FeeAmount             Money
+	TaxAmount             Money
+	SettleAmount          Money
+	ExchangeRate          float64
+	ErrorCode             string
+	ShortMessage          string
+	LongMessage           string
+}
+
+// Failed reports whether the payment did not go through, from its Ack when
+// PayPal returned one and from its ErrorCode otherwise.
+func (p PaymentInfo) Failed() bool {
+	if len(p.Ack) != 0 {
+		return !p.Ack.Success()
+	}
+	return len(p.ErrorCode) != 0
+}
+
+// NetAmount is the amount the seller receives after PayPal's fee, in the
+// payment currency. SettleAmount holds what ends up in the seller's primary
+// currency when the payment was converted. PayPal does not return that
+// currency: it is the payment's when there is no ExchangeRate and otherwise
+// the one given to WithSettlementCurrency, empty if none was.
+func (p PaymentInfo) NetAmount() Money {
+	return p.Amount.Sub(p.FeeAmount)
+}
+
+// ProtectionEligibility tells which of PayPal's seller protection programs
+// cover a payment. Status is Eligible, PartiallyEligible or Ineligible.
+type ProtectionEligibility struct {
+	Status          string
+	ItemNotReceived bool
+	Unauthorized    bool
+}
+
+func (p ProtectionEligibility) Eligible() bool {
+	return p.ItemNotReceived || p.Unauthorized
+}
+
+// FraudFilter is a Fraud Management Filter that fired on a payment.
+type FraudFilter struct {
+	Id   string
+	Name string
+}
+
+// FraudFilters groups the filters that fired by the action they are set up
+// with. Pending payments await review in the PayPal account, Reported ones
+// went through but were flagged.
+type FraudFilters struct {
+	Accepted []FraudFilter
+	Pending  []FraudFilter
+	Denied   []FraudFilter
+	Reported []FraudFilter
+}
+
+func (f FraudFilters) Fired() bool {
+	return len(f.Accepted)+len(f.Pending)+len(f.Denied)+len(f.Reported) != 0
+}
+
+// parseFraudFilters reads the L_FMFfilterIDn and L_FMFfilterNAMEn lists, which
+// DoExpressCheckoutPayment returns per payment as L_PAYMENTINFO_n_FMF...
+func parseFraudFilters(values url.Values, prefix string) FraudFilters {
+	list := func(action string) (filters []FraudFilter) {
+		for i := 0; ; i++ {
+			key := fmt.Sprintf("L_%sFMF%s%%s%d", prefix, action, i)
+			id, ok := values[fmt.Sprintf(key, "ID")]
+			if !ok {
+				return
+			}
+			filters = append(filters, FraudFilter{Id: id[0], Name: values.Get(fmt.Sprintf(key, "NAME"))})
+		}
+	}
+	return FraudFilters{
+		Accepted: list("ACCEPT"),
+		Pending:  list("PENDING"),
+		Denied:   list("DENY"),
+		Reported: list("REPORT"),
+	}
+}
+
+// parseProtectionEligibility reads PROTECTIONELIGIBILITYTYPE, a comma separated
+// list of the programs covering the payment. Older API versions only return
+// PROTECTIONELIGIBILITY, where PartiallyEligible means item not received
+// coverage only.
+func parseProtectionEligibility(values url.Values, prefix string) ProtectionEligibility {
+	eligibility := ProtectionEligibility{Status: values.Get(prefix + "PROTECTIONELIGIBILITY")}
+	if types := values.Get(prefix + "PROTECTIONELIGIBILITYTYPE"); len(types) != 0 {
+		for _, t := range strings.Split(types, ",") {
+			switch strings.TrimSpace(t) {
+			case "ItemNotReceivedEligible":
+				eligibility.ItemNotReceived = true
+			case "UnauthorizedPaymentEligible":
+				eligibility.Unauthorized = true
+			}
+		}
+		return eligibility
+	}
+	switch eligibility.Status {
+	case "Eligible":
+		eligibility.ItemNotReceived, eligibility.Unauthorized = true, true
+	case "PartiallyEligible":
+		eligibility.ItemNotReceived = true
+	}
+	return eligibility
+}
+
+// LineItem is an order line. Number is the merchant's item number or SKU and
+// ItemUrl links the item on the PayPal review page and in receipts. Category is
+// "Digital" or "Physical".
+type LineItem struct {
+	Name        string
+	Description string
+	Number      string
+	ItemUrl     string
+	Category    string
+	Quantity    int
+	Amount      float64
+	TaxAmount   float64
+}
+
+// ItemDetails is a line item as PayPal returns it, with amounts in the
+// currency of the payment.
+type ItemDetails struct {
+	Name        string
+	Description string
+	Number      string
+	ItemUrl     string
+	Category    string
+	Quantity    int
+	Amount      Money
+	TaxAmount   Money
+}
+
+func parsePayer(values url.Values) Payer {
+	return Payer{
+		PayerId:     values.Get("PAYERID"),
+		Email:       values.Get("EMAIL"),
+		FirstName:   values.Get("FIRSTNAME"),
+		LastName:    values.Get("LASTNAME"),
+		Business:    values.Get("BUSINESS"),
+		CountryCode: values.Get("COUNTRYCODE"),
+		PayerStatus: values.Get("PAYERSTATUS"),
+	}
+}
+
+func parseAddress(values url.Values, prefix string) Address {
+	return Address{
+		Name:        values.Get(prefix + "SHIPTONAME"),
+		Street:      values.Get(prefix + "SHIPTOSTREET"),
+		Street2:     values.Get(prefix + "SHIPTOSTREET2"),
+		City:        values.Get(prefix + "SHIPTOCITY"),
+		State:       values.Get(prefix + "SHIPTOSTATE"),
+		Zip:         values.Get(prefix + "SHIPTOZIP"),
+		CountryCode: normalizeCountryCode(values.Get(prefix + "SHIPTOCOUNTRYCODE")),
+		Phone:       values.Get(prefix + "SHIPTOPHONENUM"),
+		Status:      AddressStatus(values.Get(prefix + "ADDRESSSTATUS")),
+	}
+}
+
+func addAddress(values url.Values, prefix string, address *Address) {
+	fields := map[string]string{
+		"SHIPTONAME":        address.Name,
+		"SHIPTOSTREET":      address.Street,
+		"SHIPTOSTREET2":     address.Street2,
+		"SHIPTOCITY":        address.City,
+		"SHIPTOSTATE":       address.State,
+		"SHIPTOZIP":         address.Zip,
+		"SHIPTOCOUNTRYCODE": normalizeCountryCode(address.CountryCode),
+		"SHIPTOPHONENUM":    address.Phone,
+	}
+	for key, value := range fields {
+		if len(value) != 0 {
+			values.Add(prefix+key, value)
+		}
+	}
+}
+
+func parsePaymentInfo(values url.Values, prefix, settleCurrency string) PaymentInfo {
+	currencyCode := values.Get(prefix + "CURRENCYCODE")
+	if len(values.Get(prefix+"EXCHANGERATE")) == 0 {
+		settleCurrency = currencyCode
+	}
+	// Legs that went through come with ERRORCODE 0.
+	errorCode := values.Get(prefix + "ERRORCODE")
+	if errorCode == "0" {
+		errorCode = ""
+	}
+	return PaymentInfo{
+		Ack:                   Ack(values.Get(prefix + "ACK")),
+		PaymentRequestId:      values.Get(prefix + "PAYMENTREQUESTID"),
+		SellerAccountId:       values.Get(prefix + "SELLERPAYPALACCOUNTID"),
+		TransactionId:         values.Get(prefix + "TRANSACTIONID"),
+		ParentTransactionId:   values.Get(prefix + "PARENTTRANSACTIONID"),
+		ReceiptId:             values.Get(prefix + "RECEIPTID"),
+		TransactionType:       values.Get(prefix + "TRANSACTIONTYPE"),
+		PaymentType:           values.Get(prefix + "PAYMENTTYPE"),
+		OrderTime:             parseTime(values, prefix+"ORDERTIME"),
+		PaymentStatus:         PaymentStatus(values.Get(prefix + "PAYMENTSTATUS")),
+		PendingReason:         PendingReason(values.Get(prefix + "PENDINGREASON")),
+		ReasonCode:            ReasonCode(values.Get(prefix + "REASONCODE")),
+		ProtectionEligibility: parseProtectionEligibility(values, prefix),
+		FraudFilters:          parseFraudFilters(values, prefix),
+		CurrencyCode:          currencyCode,
+		Amount:                parseMoney(values, prefix+"AMT", currencyCode),
+		FeeAmount:             parseMoney(values, prefix+"FEEAMT", currencyCode),
+		TaxAmount:             parseMoney(values, prefix+"TAXAMT", currencyCode),
+		SettleAmount:          parseMoney(values, prefix+"SETTLEAMT", settleCurrency),
+		ExchangeRate:          parseAmount(values, prefix+"EXCHANGERATE"),
+		ErrorCode:             errorCode,
+		ShortMessage:          values.Get(prefix + "SHORTMESSAGE"),
+		LongMessage:           values.Get(prefix + "LONGMESSAGE"),
+	}
+}
+
+// parseLineItems reads the L_<prefix>NAMEn family of keys, stopping at the
+// first index for which PayPal returned neither a name nor an amount.
+func parseLineItems(values url.Values, prefix, currencyCode string) (items []ItemDetails) {
+	for i := 0; ; i++ {
+		key := func(name string) string {
+			return fmt.Sprintf("L_%s%s%d", prefix, name, i)
+		}
+		if _, ok := values[key("NAME")]; !ok {
+			if _, ok := values[key("AMT")]; !ok {
+				return
+			}
+		}
+
+		quantity, _ := strconv.Atoi(values.Get(key("QTY")))
+		items = append(items, ItemDetails{
+			Name:        values.Get(key("NAME")),
+			Description: values.Get(key("DESC")),
+			Number:      values.Get(key("NUMBER")),
+			ItemUrl:     values.Get(key("ITEMURL")),
+			Category:    values.Get(key("ITEMCATEGORY")),
+			Quantity:    quantity,
+			Amount:      parseMoney(values, key("AMT"), currencyCode),
+			TaxAmount:   parseMoney(values, key("TAXAMT"), currencyCode),
+		})
+	}
+}
+
+// addLineItems writes items as the L_<prefix>NAMEn family of keys and returns
+// their item and tax totals.
+func addLineItems(values url.Values, prefix string, items []LineItem) (itemAmount, taxAmount float64) {
+	for i, item := range items {
+		key := func(name string) string {
+			return fmt.Sprintf("L_%s%s%d", prefix, name, i)
+		}
+		values.Add(key("NAME"), item.Name)
+		if len(item.Description) != 0 {
+			values.Add(key("DESC"), item.Description)
+		}
+		if len(item.Number) != 0 {
+			values.Add(key("NUMBER"), item.Number)
+		}
+		if len(item.ItemUrl) != 0 {
+			values.Add(key("ITEMURL"), item.ItemUrl)
+		}
+		if len(item.Category) != 0 {
+			values.Add(key("ITEMCATEGORY"), item.Category)
+		}
+		values.Add(key("QTY"), fmt.Sprintf("%d", item.Quantity))
+		values.Add(key("AMT"), fmt.Sprintf("%.2f", item.Amount))
+		if item.TaxAmount != 0 {
+			values.Add(key("TAXAMT"), fmt.Sprintf("%.2f", item.TaxAmount))
+		}
+
+		itemAmount += item.Amount * float64(item.Quantity)
+		taxAmount += item.TaxAmount * float64(item.Quantity)
+	}
+	return
+}